@@ -0,0 +1,207 @@
+// Command gomask masks sensitive values in JSON, NDJSON, CSV, TSV, YAML and plain text files.
+//
+// Usage:
+//
+//	gomask [flags] [file ...]
+//
+// Each file is masked according to its format, given by -format or else by its extension, and written
+// to standard output, or back to the file with -w. With no files, standard input is masked and -format
+// is required.
+//
+// The policy file, in YAML or JSON, selects the values to mask using the same masker tags as struct
+// tags:
+//
+//	# object keys of JSON, NDJSON and YAML files, and CSV and TSV column names
+//	keys:
+//	  ignoreCase: true
+//	  rules:
+//	    - {key: password, mask: "*"}
+//	    - {key: "*_token", mask: X}
+//	# paths within JSON, NDJSON and YAML documents
+//	paths:
+//	  - {path: "$.users[*].ssn", mask: "X,showback=4"}
+//	# CSV and TSV columns by name or zero-based index
+//	columns:
+//	  - {name: email, mask: "X,showfront=2"}
+//	  - {index: 3, mask: X}
+//	# built-in detectors for plain text files: email, card, ssn, ip and phone
+//	detect: [email, card]
+//	# matches of regular expressions in plain text files, optionally validated by luhn or ssn
+//	text:
+//	  - {name: ssn, pattern: '\b\d{3}-\d{2}-\d{4}\b', validate: ssn, mask: X}
+//
+// Without a policy file, masking.DefaultKeyPolicy is used for keys and column names. Plain text is
+// masked by all of the built-in detectors, and any registered by plugins, unless detect or text rules
+// are given. The "detect" masker may also be used in key and column rules to mask the values found in
+// free-text fields.
+//
+// Custom maskers and detectors are loaded from Go plugins with -plugin. A plugin must export a
+// function RegisterMaskers(*masking.Registry) error.
+//
+// Object keys of masked JSON and YAML documents are written in sorted order.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dgravesa/go-mask/masking"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// stringsFlag is a flag that may be given more than once.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// run runs the command with the given arguments and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gomask", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	policyPath := flags.String("policy", "", "policy `file` selecting the values to mask")
+	format := flags.String("format", "",
+		"input `format`: "+strings.Join(formats, ", ")+" (default: by file extension)")
+	write := flags.Bool("w", false, "write masked output back to each file instead of standard output")
+	dryRun := flags.Bool("dry-run", false, "report what would be masked without writing output")
+	summary := flags.Bool("summary", false, "report a summary of masked values to standard error")
+	noHeader := flags.Bool("no-header", false, "CSV and TSV input has no header record")
+	var plugins stringsFlag
+	flags.Var(&plugins, "plugin", "load custom maskers from a Go plugin `file` (may be repeated)")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: gomask [flags] [file ...]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	fail := func(err error) int {
+		fmt.Fprintf(stderr, "gomask: %v\n", err)
+		return 1
+	}
+
+	if *format != "" && !contains(formats, *format) {
+		return fail(fmt.Errorf("unknown format \"%s\"", *format))
+	}
+	files := flags.Args()
+	if len(files) == 0 && *format == "" {
+		return fail(fmt.Errorf("-format is required when reading standard input"))
+	}
+	if len(files) == 0 && *write {
+		return fail(fmt.Errorf("-w cannot be used with standard input"))
+	}
+
+	fm := &fileMasker{
+		registry: masking.NewRegistry(),
+		policy:   defaultPolicy(),
+		noHeader: *noHeader,
+	}
+	for _, path := range plugins {
+		if err := loadPlugin(path, fm.registry); err != nil {
+			return fail(err)
+		}
+	}
+	if *policyPath != "" {
+		f, err := os.Open(*policyPath)
+		if err != nil {
+			return fail(err)
+		}
+		fm.policy, err = loadPolicy(f)
+		f.Close()
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	maskFile := func(name string, data []byte, format string) ([]byte, error) {
+		masked, c, err := fm.mask(data, format)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if *summary || *dryRun {
+			printSummary(stderr, name, c)
+		}
+		return masked, nil
+	}
+
+	if len(files) == 0 {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return fail(err)
+		}
+		masked, err := maskFile("<stdin>", data, *format)
+		if err != nil {
+			return fail(err)
+		}
+		if !*dryRun {
+			stdout.Write(masked)
+		}
+		return 0
+	}
+
+	for _, name := range files {
+		fileFormat := *format
+		if fileFormat == "" {
+			fileFormat = formatOf(name)
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return fail(err)
+		}
+		masked, err := maskFile(name, data, fileFormat)
+		if err != nil {
+			return fail(err)
+		}
+
+		switch {
+		case *dryRun:
+		case *write:
+			info, err := os.Stat(name)
+			if err != nil {
+				return fail(err)
+			}
+			if err := os.WriteFile(name, masked, info.Mode().Perm()); err != nil {
+				return fail(err)
+			}
+		default:
+			stdout.Write(masked)
+		}
+	}
+	return 0
+}
+
+// printSummary writes the number of masked values of the named input by field.
+func printSummary(w io.Writer, name string, c counts) {
+	fmt.Fprintf(w, "%s: %d values masked\n", name, c.total())
+	fields := make([]string, 0, len(c))
+	for field := range c {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		fmt.Fprintf(w, "  %s: %d\n", field, c[field])
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
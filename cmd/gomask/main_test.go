@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPolicy = `
+keys:
+  ignoreCase: true
+  rules:
+    - {key: password, mask: "*"}
+    - {key: pin, mask: X}
+paths:
+  - {path: "$.users[*].ssn", mask: "X,showback=4"}
+columns:
+  - {name: email, mask: "X,showfront=2"}
+  - {index: 0, mask: x}
+text:
+  - {name: ssn, pattern: '\b\d{3}-\d{2}-\d{4}\b', mask: X}
+`
+
+// writeFiles writes the named files to a temporary directory and returns its path.
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)
+		assert.NoError(t, err)
+	}
+	return dir
+}
+
+func Test_run_WithFilesOfEachFormat_WritesMaskedOutput(t *testing.T) {
+	// arrange
+	testCases := map[string]struct {
+		name     string
+		content  string
+		expected string
+	}{
+		"json": {
+			name:    "users.json",
+			content: `{"users": [{"name": "Jane", "ssn": "123-45-6789", "pin": 1234}], "Password": "hunter2"}`,
+			expected: "{\n  \"Password\": \"*******\",\n  \"users\": [\n    {\n      \"name\": \"Jane\",\n" +
+				"      \"pin\": \"XXXX\",\n      \"ssn\": \"XXXXXXX6789\"\n    }\n  ]\n}\n",
+		},
+		"ndjson": {
+			name:     "events.ndjson",
+			content:  "{\"user\":\"jane\",\"password\":\"hunter2\"}\n\n{\"user\":\"john\",\"pin\":42}\n",
+			expected: "{\"password\":\"*******\",\"user\":\"jane\"}\n\n{\"pin\":\"XX\",\"user\":\"john\"}\n",
+		},
+		"csv": {
+			name:     "users.csv",
+			content:  "id,email,password\n17,jane@example.com,hunter2\n",
+			expected: "id,email,password\nxx,jaXXXXXXXXXXXXXX,*******\n",
+		},
+		"tsv": {
+			name:     "users.tsv",
+			content:  "id\temail\n17\tjane@example.com\n",
+			expected: "id\temail\nxx\tjaXXXXXXXXXXXXXX\n",
+		},
+		"yaml": {
+			name:     "users.yaml",
+			content:  "users:\n  - name: Jane\n    ssn: 123-45-6789\n    pin: 1234\n",
+			expected: "users:\n  - name: Jane\n    pin: XXXX\n    ssn: XXXXXXX6789\n",
+		},
+		"text": {
+			name:     "notes.txt",
+			content:  "caller 123-45-6789 asked about 555-1234\n",
+			expected: "caller XXXXXXXXXXX asked about 555-1234\n",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"policy.yaml": testPolicy,
+				tc.name:       tc.content,
+			})
+			var stdout, stderr bytes.Buffer
+
+			// act
+			code := run([]string{"-policy", filepath.Join(dir, "policy.yaml"), filepath.Join(dir, tc.name)},
+				nil, &stdout, &stderr)
+
+			// assert
+			assert.Equal(t, 0, code, stderr.String())
+			assert.Equal(t, tc.expected, stdout.String())
+		})
+	}
+}
+
+func Test_run_WithStdin_UsesFormatFlagAndDefaultPolicy(t *testing.T) {
+	// arrange
+	stdin := strings.NewReader(`{"username": "jsmith", "password": "hunter2"}`)
+	var stdout, stderr bytes.Buffer
+
+	// act
+	code := run([]string{"-format", "json"}, stdin, &stdout, &stderr)
+
+	// assert
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, "{\n  \"password\": \"*******\",\n  \"username\": \"jsmith\"\n}\n", stdout.String())
+}
+
+func Test_run_WithYAMLNonStringKeys_MasksNestedValues(t *testing.T) {
+	// arrange
+	stdin := strings.NewReader("1:\n  password: hunter2\nusers:\n  - password: abc\n")
+	var stdout, stderr bytes.Buffer
+
+	// act
+	code := run([]string{"-format", "yaml"}, stdin, &stdout, &stderr)
+
+	// assert
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, "\"1\":\n  password: '*******'\nusers:\n  - password: '***'\n", stdout.String())
+}
+
+func Test_run_WithTextAndNoTextRules_UsesDefaultDetectors(t *testing.T) {
+	// arrange
+	stdin := strings.NewReader("Reach jane@example.com or 555-123-4567 about card 4111 1111 1111 1111.\n")
+	var stdout, stderr bytes.Buffer
+
+	// act
+	code := run([]string{"-format", "text", "-summary"}, stdin, &stdout, &stderr)
+
+	// assert
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, "Reach XXXX@XXXXXXX.XXX or XXX-XXX-4567 about card XXXX XXXX XXXX 1111.\n", stdout.String())
+	assert.Equal(t, "<stdin>: 3 values masked\n  card: 1\n  email: 1\n  phone: 1\n", stderr.String())
+}
+
+func Test_run_WithDetectPolicy_UsesSelectedDetectors(t *testing.T) {
+	// arrange
+	dir := writeFiles(t, map[string]string{
+		"policy.yaml": "detect: [email]\ntext:\n  - {name: account, pattern: 'ACCT-\\d+', mask: '*,showfront=5'}\n",
+		"notes.txt":   "ACCT-9876 belongs to jane@example.com, 555-123-4567\n",
+	})
+	var stdout, stderr bytes.Buffer
+
+	// act
+	code := run([]string{"-policy", filepath.Join(dir, "policy.yaml"), filepath.Join(dir, "notes.txt")},
+		nil, &stdout, &stderr)
+
+	// assert
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, "ACCT-**** belongs to XXXX@XXXXXXX.XXX, 555-123-4567\n", stdout.String())
+}
+
+func Test_run_WithDryRun_ReportsSummaryWithoutWriting(t *testing.T) {
+	// arrange
+	dir := writeFiles(t, map[string]string{
+		"policy.yaml": testPolicy,
+		"users.json":  `{"users": [{"ssn": "123-45-6789"}, {"ssn": "987-65-4321", "pin": 1}]}`,
+	})
+	var stdout, stderr bytes.Buffer
+
+	// act
+	code := run([]string{"-policy", filepath.Join(dir, "policy.yaml"), "-dry-run", "-w",
+		filepath.Join(dir, "users.json")}, nil, &stdout, &stderr)
+
+	// assert
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, "", stdout.String())
+	assert.Equal(t, filepath.Join(dir, "users.json")+": 3 values masked\n"+
+		"  $.users[*].pin: 1\n"+
+		"  $.users[*].ssn: 2\n", stderr.String())
+	content, _ := os.ReadFile(filepath.Join(dir, "users.json"))
+	assert.Contains(t, string(content), "123-45-6789")
+}
+
+func Test_run_WithWriteFlag_OverwritesFiles(t *testing.T) {
+	// arrange
+	dir := writeFiles(t, map[string]string{
+		"a.ndjson": "{\"password\":\"a\"}\n",
+		"b.ndjson": "{\"password\":\"bb\"}\n",
+	})
+	var stdout, stderr bytes.Buffer
+
+	// act
+	code := run([]string{"-w", filepath.Join(dir, "a.ndjson"), filepath.Join(dir, "b.ndjson")},
+		nil, &stdout, &stderr)
+
+	// assert
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, "", stdout.String())
+	a, _ := os.ReadFile(filepath.Join(dir, "a.ndjson"))
+	b, _ := os.ReadFile(filepath.Join(dir, "b.ndjson"))
+	assert.Equal(t, "{\"password\":\"*\"}\n", string(a))
+	assert.Equal(t, "{\"password\":\"**\"}\n", string(b))
+}
+
+func Test_run_WithInvalidArguments_Fails(t *testing.T) {
+	// arrange
+	dir := writeFiles(t, map[string]string{
+		"bad-policy.yaml": "keys:\n  rules:\n    - {key: password}\n",
+		"bad-detect.yaml": "detect: [passport]\n",
+		"users.json":      `{"password": "hunter2"}`,
+		"broken.json":     `{"password": `,
+		"duplicate.yaml":  "1: {password: a}\n1.0: {password: b}\n",
+	})
+	testCases := map[string][]string{
+		"stdin without format": {},
+		"stdin with write":     {"-format", "json", "-w"},
+		"unknown format":       {"-format", "xml", filepath.Join(dir, "users.json")},
+		"invalid policy":       {"-policy", filepath.Join(dir, "bad-policy.yaml"), filepath.Join(dir, "users.json")},
+		"unknown detector":     {"-policy", filepath.Join(dir, "bad-detect.yaml"), filepath.Join(dir, "users.json")},
+		"missing file":         {filepath.Join(dir, "missing.json")},
+		"malformed input":      {filepath.Join(dir, "broken.json")},
+		"ambiguous yaml keys":  {filepath.Join(dir, "duplicate.yaml")},
+		"missing plugin":       {"-plugin", filepath.Join(dir, "missing.so"), filepath.Join(dir, "users.json")},
+	}
+
+	for name, args := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			// act
+			code := run(args, strings.NewReader(""), &stdout, &stderr)
+
+			// assert
+			assert.Equal(t, 1, code)
+			assert.Equal(t, "", stdout.String())
+			assert.True(t, strings.HasPrefix(stderr.String(), "gomask: "), stderr.String())
+		})
+	}
+}
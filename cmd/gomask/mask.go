@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dgravesa/go-mask/masking"
+)
+
+// formats lists the supported input formats.
+var formats = []string{"json", "ndjson", "csv", "tsv", "yaml", "text"}
+
+// formatOf returns the input format of the named file based on its extension.
+func formatOf(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return "json"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	case ".csv":
+		return "csv"
+	case ".tsv":
+		return "tsv"
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+	return "text"
+}
+
+// counts holds the number of values masked by field, such as a key path, column or text rule.
+type counts map[string]int
+
+// fileMasker masks the contents of files by format.
+type fileMasker struct {
+	registry *masking.Registry
+	policy   *policy
+	// noHeader indicates that CSV and TSV input has no header record.
+	noHeader bool
+}
+
+// mask returns a masked copy of data and the number of values masked by field.
+func (fm *fileMasker) mask(data []byte, format string) ([]byte, counts, error) {
+	switch format {
+	case "json":
+		return fm.maskJSON(data)
+	case "ndjson":
+		return fm.maskNDJSON(data)
+	case "csv":
+		return fm.maskCSV(data, ',')
+	case "tsv":
+		return fm.maskCSV(data, '\t')
+	case "yaml":
+		return fm.maskYAML(data)
+	case "text":
+		return fm.maskText(data)
+	}
+	return nil, nil, fmt.Errorf("unknown format \"%s\"", format)
+}
+
+func (fm *fileMasker) maskJSON(data []byte) ([]byte, counts, error) {
+	c := make(counts)
+	masked, err := fm.maskJSONDocument(data, c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, masked, "", "  "); err != nil {
+		return nil, nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), c, nil
+}
+
+func (fm *fileMasker) maskNDJSON(data []byte) ([]byte, counts, error) {
+	c := make(counts)
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			out.WriteByte('\n')
+			continue
+		}
+		masked, err := fm.maskJSONDocument(scanner.Bytes(), c)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		out.Write(masked)
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), c, scanner.Err()
+}
+
+// maskJSONDocument returns a masked copy of a single JSON document, adding the masked values to c.
+func (fm *fileMasker) maskJSONDocument(data []byte, c counts) ([]byte, error) {
+	decode := func() (interface{}, error) {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var v interface{}
+		err := decoder.Decode(&v)
+		return v, err
+	}
+	original, err := decode()
+	if err != nil {
+		return nil, err
+	}
+	v, _ := decode()
+
+	v, err = fm.registry.MaskJSONValue(v, fm.policy.json)
+	if err != nil {
+		return nil, err
+	}
+	countChanges(original, v, "$", c)
+	return json.Marshal(v)
+}
+
+func (fm *fileMasker) maskYAML(data []byte) ([]byte, counts, error) {
+	c := make(counts)
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+
+	originals := yaml.NewDecoder(bytes.NewReader(data))
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var original, v interface{}
+		if err := originals.Decode(&original); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		decoder.Decode(&v)
+		original, err := stringKeys(original)
+		if err != nil {
+			return nil, nil, err
+		}
+		v, _ = stringKeys(v)
+
+		v, err = fm.registry.MaskJSONValue(v, fm.policy.json)
+		if err != nil {
+			return nil, nil, err
+		}
+		countChanges(original, v, "$", c)
+		if err := encoder.Encode(v); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, err
+	}
+	return out.Bytes(), c, nil
+}
+
+// stringKeys returns v with each mapping decoded as map[interface{}]interface{}, as yaml does for
+// mappings with keys other than strings, converted to map[string]interface{} so that its values are
+// masked like those of other mappings. Keys are converted to their string form.
+func stringKeys(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			converted, err := stringKeys(val)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = converted
+		}
+		return v, nil
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			converted, err := stringKeys(val)
+			if err != nil {
+				return nil, err
+			}
+			k := fmt.Sprint(key)
+			if _, found := m[k]; found {
+				return nil, fmt.Errorf("yaml: mapping has more than one key %q", k)
+			}
+			m[k] = converted
+		}
+		return m, nil
+	case []interface{}:
+		for i, item := range v {
+			converted, err := stringKeys(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+		return v, nil
+	}
+	return v, nil
+}
+
+func (fm *fileMasker) maskCSV(data []byte, comma rune) ([]byte, counts, error) {
+	var out bytes.Buffer
+	masker := fm.registry.NewCSVMasker(bytes.NewReader(data), &out, fm.policy.csv)
+	masker.Comma = comma
+	masker.NoHeader = fm.noHeader
+	if err := masker.MaskAll(); err != nil {
+		return nil, nil, err
+	}
+
+	// count the masked values by comparing columns of the input and output
+	c := make(counts)
+	original := csv.NewReader(bytes.NewReader(data))
+	original.Comma = comma
+	masked := csv.NewReader(bytes.NewReader(out.Bytes()))
+	masked.Comma = comma
+	var header []string
+	if !fm.noHeader {
+		var err error
+		header, err = original.Read()
+		if err == io.EOF {
+			return out.Bytes(), c, nil
+		} else if err != nil {
+			return nil, nil, err
+		}
+		masked.Read()
+	}
+	for {
+		before, err := original.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		after, err := masked.Read()
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range before {
+			if i >= len(after) || before[i] == after[i] {
+				continue
+			}
+			if i < len(header) {
+				c[header[i]]++
+			} else {
+				c[fmt.Sprintf("column %d", i)]++
+			}
+		}
+	}
+	return out.Bytes(), c, nil
+}
+
+func (fm *fileMasker) maskText(data []byte) ([]byte, counts, error) {
+	text := string(data)
+	c := make(counts)
+	for _, m := range fm.registry.Detect(text, fm.policy.detectors...) {
+		c[m.Kind]++
+	}
+
+	masked, err := fm.registry.MaskText(text, fm.policy.detectors...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []byte(masked), c, nil
+}
+
+// countChanges adds the leaf values that differ between before and after to c, by path. Array indexes
+// are counted together as "[*]".
+func countChanges(before, after interface{}, path string, c counts) {
+	switch b := before.(type) {
+	case map[string]interface{}:
+		if a, ok := after.(map[string]interface{}); ok {
+			for key, item := range b {
+				countChanges(item, a[key], path+"."+key, c)
+			}
+			return
+		}
+	case []interface{}:
+		if a, ok := after.([]interface{}); ok && len(a) == len(b) {
+			for i := range b {
+				countChanges(b[i], a[i], path+"[*]", c)
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		c[path]++
+	}
+}
+
+// total returns the total number of masked values.
+func (c counts) total() int {
+	n := 0
+	for _, count := range c {
+		n += count
+	}
+	return n
+}
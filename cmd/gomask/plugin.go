@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/dgravesa/go-mask/masking"
+)
+
+// pluginSymbol is the function a plugin exports to register its maskers.
+const pluginSymbol = "RegisterMaskers"
+
+// loadPlugin opens the Go plugin at path and registers its maskers with r.
+//
+// The plugin must export a function named RegisterMaskers with the signature
+// func(*masking.Registry) error, which typically calls masking.RegisterMaskerIn.
+func loadPlugin(path string, r *masking.Registry) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", path, err)
+	}
+
+	sym, err := p.Lookup(pluginSymbol)
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", path, err)
+	}
+	register, ok := sym.(func(*masking.Registry) error)
+	if !ok {
+		return fmt.Errorf("plugin %s: %s must have type func(*masking.Registry) error", path, pluginSymbol)
+	}
+
+	if err := register(r); err != nil {
+		return fmt.Errorf("plugin %s: %w", path, err)
+	}
+	return nil
+}
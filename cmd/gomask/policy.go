@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dgravesa/go-mask/masking"
+)
+
+// policyFile is the format of the policy file given by the -policy flag.
+type policyFile struct {
+	Keys    *keysSpec    `yaml:"keys"`
+	Paths   []pathSpec   `yaml:"paths"`
+	Columns []columnSpec `yaml:"columns"`
+	Text    []textSpec   `yaml:"text"`
+	Detect  []string     `yaml:"detect"`
+}
+
+type keysSpec struct {
+	IgnoreCase bool      `yaml:"ignoreCase"`
+	Rules      []keySpec `yaml:"rules"`
+}
+
+type keySpec struct {
+	Key  string `yaml:"key"`
+	Mask string `yaml:"mask"`
+}
+
+type pathSpec struct {
+	Path string `yaml:"path"`
+	Mask string `yaml:"mask"`
+}
+
+type columnSpec struct {
+	Name  string `yaml:"name"`
+	Index *int   `yaml:"index"`
+	Mask  string `yaml:"mask"`
+}
+
+type textSpec struct {
+	Name     string `yaml:"name"`
+	Pattern  string `yaml:"pattern"`
+	Validate string `yaml:"validate"`
+	Mask     string `yaml:"mask"`
+}
+
+// builtinDetectors are the built-in detectors that may be selected by kind with "detect".
+var builtinDetectors = map[string]*masking.RegexDetector{
+	masking.EmailDetector.Kind:      masking.EmailDetector,
+	masking.CardNumberDetector.Kind: masking.CardNumberDetector,
+	masking.SSNDetector.Kind:        masking.SSNDetector,
+	masking.IPDetector.Kind:         masking.IPDetector,
+	masking.PhoneDetector.Kind:      masking.PhoneDetector,
+}
+
+// validators are the validators that may be given to text rules.
+var validators = map[string]func(string) bool{
+	"luhn": masking.LuhnValid,
+	"ssn":  masking.ValidSSN,
+}
+
+// policy selects the values to mask in each input format.
+type policy struct {
+	json masking.JSONPolicy
+	csv  masking.CSVPolicy
+	// detectors find the values to mask in plain text. If empty, the detectors of the registry are
+	// used, which include any registered by plugins.
+	detectors []masking.Detector
+}
+
+// defaultPolicy returns the policy used when no policy file is given.
+func defaultPolicy() *policy {
+	return &policy{
+		json: masking.JSONPolicy{Keys: masking.DefaultKeyPolicy},
+		csv:  masking.CSVPolicy{Columns: masking.DefaultKeyPolicy},
+	}
+}
+
+// loadPolicy reads a policy file in YAML or JSON format.
+func loadPolicy(r io.Reader) (*policy, error) {
+	var spec policyFile
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("policy: %w", err)
+	}
+
+	p := defaultPolicy()
+	if spec.Keys != nil {
+		p.json.Keys = masking.KeyPolicy{IgnoreCase: spec.Keys.IgnoreCase}
+		for _, rule := range spec.Keys.Rules {
+			if rule.Key == "" || rule.Mask == "" {
+				return nil, fmt.Errorf("policy: key rules require key and mask")
+			}
+			p.json.Keys.Rules = append(p.json.Keys.Rules, masking.KeyRule{Key: rule.Key, Mask: rule.Mask})
+		}
+	}
+
+	for _, rule := range spec.Paths {
+		if rule.Mask == "" {
+			return nil, fmt.Errorf("policy: path %s: mask is required", rule.Path)
+		}
+		p.json.Paths = append(p.json.Paths, masking.PathRule{Path: rule.Path, Mask: rule.Mask})
+	}
+	if err := p.json.Validate(); err != nil {
+		return nil, fmt.Errorf("policy: %w", err)
+	}
+
+	// columns are selected by name before falling back to the key rules
+	p.csv.Columns = masking.KeyPolicy{IgnoreCase: p.json.Keys.IgnoreCase}
+	for _, rule := range spec.Columns {
+		switch {
+		case rule.Mask == "":
+			return nil, fmt.Errorf("policy: column rules require mask")
+		case rule.Index != nil && rule.Name == "":
+			if p.csv.Indexes == nil {
+				p.csv.Indexes = make(map[int]string)
+			}
+			p.csv.Indexes[*rule.Index] = rule.Mask
+		case rule.Index == nil && rule.Name != "":
+			p.csv.Columns.Rules = append(p.csv.Columns.Rules, masking.KeyRule{Key: rule.Name, Mask: rule.Mask})
+		default:
+			return nil, fmt.Errorf("policy: column rules require exactly one of name or index")
+		}
+	}
+	p.csv.Columns.Rules = append(p.csv.Columns.Rules, p.json.Keys.Rules...)
+
+	for _, kind := range spec.Detect {
+		detector, found := builtinDetectors[kind]
+		if !found {
+			return nil, fmt.Errorf("policy: unknown detector \"%s\"", kind)
+		}
+		p.detectors = append(p.detectors, detector)
+	}
+	for _, rule := range spec.Text {
+		if rule.Pattern == "" || rule.Mask == "" {
+			return nil, fmt.Errorf("policy: text rules require pattern and mask")
+		}
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("policy: text rule %s: %w", rule.Name, err)
+		}
+		detector := &masking.RegexDetector{
+			Kind:    rule.Name,
+			Pattern: pattern,
+			Mask:    rule.Mask,
+		}
+		if detector.Kind == "" {
+			detector.Kind = rule.Pattern
+		}
+		if rule.Validate != "" {
+			detector.Validate = validators[rule.Validate]
+			if detector.Validate == nil {
+				return nil, fmt.Errorf("policy: text rule %s: unknown validator \"%s\"", rule.Name, rule.Validate)
+			}
+		}
+		p.detectors = append(p.detectors, detector)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,174 @@
+package masking
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ClassPolicy maps data classifications to masker tags.
+//
+// Fields are classified with tags such as `pii:"email"` or `class:"secret"`, and a ClassPolicy in use
+// by a Registry gives the masker tag of each classification, so that a redaction standard is changed
+// in one place rather than on every field. Masker tags may differ by profile, as given to MaskFor:
+//
+//	tags: [pii, class]
+//	classes:
+//	  email: X,showfront=2
+//	  ssn: X,showback=4
+//	  secret: "*"
+//	profiles:
+//	  audit:
+//	    ssn: ""
+//	    email: ""
+//
+// An empty masker tag for a profile leaves fields of the classification unmasked for the profile.
+// Masker tags of a class policy apply only to fields without a mask tag, field rule or field policy.
+type ClassPolicy struct {
+	// TagKeys are the struct tag keys of classifications in order of precedence. If empty, "class" is
+	// used.
+	TagKeys []string `yaml:"tags"`
+	// Classes maps classifications to masker tags.
+	Classes map[string]string `yaml:"classes"`
+	// Profiles maps profiles to classifications to masker tags that take precedence over Classes for
+	// the profile.
+	Profiles map[string]map[string]string `yaml:"profiles"`
+}
+
+// defaultClassTagKey is the struct tag key of classifications, as in `class:"secret"`, if a class
+// policy gives none.
+const defaultClassTagKey = "class"
+
+// LoadClassPolicy reads a class policy in YAML or JSON format from r.
+func LoadClassPolicy(r io.Reader) (*ClassPolicy, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var p ClassPolicy
+	if err := decoder.Decode(&p); err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("class policy: empty policy")
+		}
+		return nil, fmt.Errorf("class policy: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate reports any invalid tag keys, empty classifications or masker tags, and profiles that refer
+// to classifications not given by Classes.
+func (p *ClassPolicy) Validate() error {
+	if len(p.TagKeys) > 0 {
+		if err := validateTagKeys(p.TagKeys); err != nil {
+			return fmt.Errorf("class policy: %w", err)
+		}
+	}
+
+	var problems []string
+	for class, maskTag := range p.Classes {
+		if class == "" {
+			problems = append(problems, "empty classification")
+		} else if maskTag == "" {
+			problems = append(problems, fmt.Sprintf("empty mask tag for classification \"%s\"", class))
+		}
+	}
+	for profile, classes := range p.Profiles {
+		if err := validateProfile(profile); err != nil || profile == "" {
+			problems = append(problems, fmt.Sprintf("invalid profile name \"%s\"", profile))
+		}
+		for class := range classes {
+			if _, found := p.Classes[class]; !found {
+				problems = append(problems,
+					fmt.Sprintf("profile \"%s\": unknown classification \"%s\"", profile, class))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("class policy: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// UseClassPolicy adds p to the class policies consulted by DefaultRegistry.
+func UseClassPolicy(p *ClassPolicy) error {
+	return DefaultRegistry.UseClassPolicy(p)
+}
+
+// UseClassPolicy adds p to the class policies consulted by r for classified fields without a mask tag.
+//
+// Class policies added later take precedence over those added earlier. An error is returned if p is
+// invalid or refers to a masker that is not registered with r.
+func (r *Registry) UseClassPolicy(p *ClassPolicy) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	for _, maskTag := range p.Classes {
+		if _, err := r.getFieldMasker(maskTag); err != nil {
+			return fmt.Errorf("class policy: %w", err)
+		}
+	}
+	for _, classes := range p.Profiles {
+		for _, maskTag := range classes {
+			if maskTag == "" {
+				continue
+			}
+			if _, err := r.getFieldMasker(maskTag); err != nil {
+				return fmt.Errorf("class policy: %w", err)
+			}
+		}
+	}
+
+	r.classPolicies = append(r.classPolicies, p)
+	return nil
+}
+
+// tagKeys returns the struct tag keys of classifications of p.
+func (p *ClassPolicy) tagKeys() []string {
+	if len(p.TagKeys) == 0 {
+		return []string{defaultClassTagKey}
+	}
+	return p.TagKeys
+}
+
+// maskTag returns the masker tag of p for the classification class and profile, and whether p gives
+// one.
+func (p *ClassPolicy) maskTag(class, profile string) (string, bool) {
+	if maskTag, found := p.Profiles[profile][class]; found && profile != "" {
+		return maskTag, true
+	}
+	maskTag, found := p.Classes[class]
+	return maskTag, found
+}
+
+// classMaskTag returns the masker tag for the classification of field from the class policies of r,
+// and whether the field is classified. An error is returned if the field is classified but no class
+// policy gives a masker tag for its classification.
+func (r *Registry) classMaskTag(field reflect.StructField, profile string) (string, bool, error) {
+	var unknown string
+	for i := len(r.classPolicies) - 1; i >= 0; i-- {
+		p := r.classPolicies[i]
+		for _, key := range p.tagKeys() {
+			class := strings.TrimSpace(field.Tag.Get(key))
+			if class == "" {
+				continue
+			}
+			if maskTag, found := p.maskTag(class, profile); found {
+				return maskTag, true, nil
+			}
+			unknown = class
+		}
+	}
+
+	if unknown != "" {
+		return "", true, fmt.Errorf("mask: field %s has unknown classification \"%s\"", field.Name, unknown)
+	}
+	return "", false, nil
+}
@@ -0,0 +1,190 @@
+package masking_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UseClassPolicy_WithClassifiedFields_MasksByClassification(t *testing.T) {
+	// arrange
+	policy, err := masking.LoadClassPolicy(strings.NewReader(`
+tags: [pii, class]
+classes:
+  email: X,showfront=2
+  ssn: X,showback=4
+  secret: "*"
+`))
+	assert.NoError(t, err)
+	r := masking.NewRegistry()
+	err = r.UseClassPolicy(policy)
+	assert.NoError(t, err)
+	type User struct {
+		Email    string `json:"email" pii:"email"`
+		SSN      string `pii:"ssn"`
+		Password string `class:"secret"`
+		Nickname string `pii:"ssn" mask:"-"`
+		Name     string
+	}
+	user := User{Email: "jane@example.com", SSN: "123-45-6789", Password: "hunter2", Nickname: "jj", Name: "Jane"}
+
+	// act
+	err = r.Mask(&user)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, User{
+		Email:    "jaXXXXXXXXXXXXXX",
+		SSN:      "XXXXXXX6789",
+		Password: "*******",
+		Nickname: "--",
+		Name:     "Jane",
+	}, user)
+}
+
+func Test_UseClassPolicy_WithProfiles_UsesProfileMaskTags(t *testing.T) {
+	// arrange
+	policy, err := masking.LoadClassPolicy(strings.NewReader(`
+tags: [pii]
+classes:
+  email: X,showfront=2
+  ssn: X,showback=4
+profiles:
+  audit:
+    email: ""
+    ssn: ""
+  support:
+    ssn: "X,showback=4,alphanumeric"
+`))
+	assert.NoError(t, err)
+	r := masking.NewRegistry()
+	err = r.UseClassPolicy(policy)
+	assert.NoError(t, err)
+	type User struct {
+		Email string `pii:"email"`
+		SSN   string `pii:"ssn"`
+	}
+	testCases := map[string]User{
+		"audit":   {Email: "jane@example.com", SSN: "123-45-6789"},
+		"support": {Email: "jaXXXXXXXXXXXXXX", SSN: "XXX-XX-6789"},
+	}
+
+	for profile, expected := range testCases {
+		t.Run(profile, func(t *testing.T) {
+			user := User{Email: "jane@example.com", SSN: "123-45-6789"}
+
+			// act
+			err := r.MaskFor(context.Background(), profile, &user)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, expected, user)
+		})
+	}
+}
+
+func Test_UseClassPolicy_WithLaterPolicy_TakesPrecedence(t *testing.T) {
+	// arrange
+	r := masking.NewRegistry()
+	err := r.UseClassPolicy(&masking.ClassPolicy{
+		TagKeys: []string{"pii"},
+		Classes: map[string]string{"email": "X,showfront=2", "ssn": "X,showback=4"},
+	})
+	assert.NoError(t, err)
+	err = r.UseClassPolicy(&masking.ClassPolicy{
+		TagKeys: []string{"pii"},
+		Classes: map[string]string{"email": "*"},
+	})
+	assert.NoError(t, err)
+	type User struct {
+		Email string `pii:"email"`
+		SSN   string `pii:"ssn"`
+	}
+	user := User{Email: "jane@example.com", SSN: "123-45-6789"}
+
+	// act
+	err = r.Mask(&user)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, User{Email: "****************", SSN: "XXXXXXX6789"}, user)
+}
+
+func Test_Mask_WithUnknownClassification_ReturnsError(t *testing.T) {
+	// arrange
+	r := masking.NewRegistry()
+	err := r.UseClassPolicy(&masking.ClassPolicy{
+		TagKeys: []string{"pii"},
+		Classes: map[string]string{"email": "X"},
+	})
+	assert.NoError(t, err)
+	type Passport struct {
+		Number string `pii:"passport"`
+	}
+	passport := Passport{Number: "X1234567"}
+
+	// act
+	err = r.Mask(&passport)
+	out := fmt.Sprintf("%v", r.Fmt(passport))
+
+	// assert
+	assert.EqualError(t, err, "mask: field Number has unknown classification \"passport\"")
+	assert.Equal(t, "{[REDACTED]}", out)
+}
+
+func Test_Mask_WithoutClassPolicy_IgnoresClassificationTags(t *testing.T) {
+	// arrange
+	r := masking.NewRegistry()
+	type User struct {
+		Email string `class:"email"`
+	}
+	user := User{Email: "jane@example.com"}
+
+	// act
+	err := r.Mask(&user)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "jane@example.com", user.Email)
+}
+
+func Test_UseClassPolicy_WithInvalidPolicy_ReturnsError(t *testing.T) {
+	// arrange
+	testCases := map[string]*masking.ClassPolicy{
+		"invalid tag key":     {TagKeys: []string{"pii:"}, Classes: map[string]string{"email": "X"}},
+		"empty mask tag":      {Classes: map[string]string{"email": ""}},
+		"unrecognized masker": {Classes: map[string]string{"email": "nope"}},
+		"unknown profile class": {
+			Classes:  map[string]string{"email": "X"},
+			Profiles: map[string]map[string]string{"audit": {"ssn": ""}},
+		},
+		"invalid profile": {
+			Classes:  map[string]string{"email": "X"},
+			Profiles: map[string]map[string]string{"a b": {"email": ""}},
+		},
+	}
+
+	for name, policy := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := masking.NewRegistry()
+
+			// act
+			err := r.UseClassPolicy(policy)
+
+			// assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_LoadClassPolicy_WithUnknownField_ReturnsError(t *testing.T) {
+	// act
+	_, err := masking.LoadClassPolicy(strings.NewReader("classes:\n  email: X\nmaskers: {}\n"))
+
+	// assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package masking
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// fieldMasker is the masker of a struct field, applied only if all of its conditions hold.
+//
+// Conditions are given by "if" and "unless" arguments of the field's tag, which refer to sibling
+// fields of the same struct:
+//
+//	if=Country==US    mask if the formatted value of Country is "US"
+//	if=Country!=US    mask if the formatted value of Country is not "US"
+//	unless=Public     mask unless Public holds a non-zero value, such as true
+//
+// Pointer fields are compared by the value they point to, and a nil pointer formats as "".
+type fieldMasker struct {
+	maskFunc   maskFunc
+	conditions conditions
+}
+
+// conditions are the conditions of a field tag, all of which must hold for the field to be masked.
+type conditions []condition
+
+type condition struct {
+	unless bool
+	field  string
+	// op is "==" or "!=" to compare the field to value, or empty to test for a non-zero field
+	op    string
+	value string
+}
+
+// getFieldMasker returns the masker given by the field tag maskTag, along with its conditions.
+func (r *Registry) getFieldMasker(maskTag string) (fieldMasker, error) {
+	args, conds, err := parseFieldMaskTag(maskTag)
+	if err != nil {
+		return fieldMasker{}, err
+	}
+	maskFunc, err := r.buildMaskFunc(args)
+	if err != nil {
+		return fieldMasker{}, err
+	}
+	return fieldMasker{maskFunc: maskFunc, conditions: conds}, nil
+}
+
+// parseFieldMaskTag splits the field tag maskTag into the masker name and arguments, and its
+// conditions.
+func parseFieldMaskTag(maskTag string) ([]string, conditions, error) {
+	var args []string
+	var conds conditions
+	for _, arg := range splitMaskTag(maskTag) {
+		argName, argVal, _ := strings.Cut(arg, "=")
+		if argName != "if" && argName != "unless" {
+			args = append(args, arg)
+			continue
+		}
+
+		c, err := parseCondition(argName == "unless", argVal)
+		if err != nil {
+			return nil, nil, err
+		}
+		conds = append(conds, c)
+	}
+	return args, conds, nil
+}
+
+func parseCondition(unless bool, s string) (condition, error) {
+	c := condition{unless: unless, field: s}
+	for _, op := range []string{"!=", "=="} {
+		if field, value, found := strings.Cut(s, op); found {
+			c.field, c.op, c.value = field, op, value
+			break
+		}
+	}
+	if c.field == "" {
+		return condition{}, fmt.Errorf("condition: missing field in \"%s\"", s)
+	}
+	return c, nil
+}
+
+// validate returns an error if any condition refers to a field that is not an exported field of
+// struct type t.
+func (conds conditions) validate(t reflect.Type) error {
+	for _, c := range conds {
+		field, found := t.FieldByName(c.field)
+		if !found {
+			return fmt.Errorf("condition: %s has no field \"%s\"", t, c.field)
+		}
+		if !field.IsExported() {
+			return fmt.Errorf("condition: field \"%s\" of %s is not exported", c.field, t)
+		}
+	}
+	return nil
+}
+
+// applies reports whether all conditions of fm hold for the struct value parent.
+func (fm fieldMasker) applies(parent reflect.Value) (bool, error) {
+	if len(fm.conditions) == 0 {
+		return true, nil
+	}
+	if err := fm.conditions.validate(parent.Type()); err != nil {
+		return false, err
+	}
+
+	for _, c := range fm.conditions {
+		field, _ := parent.Type().FieldByName(c.field)
+		// a field promoted through a nil embedded pointer is treated as nil
+		val, _ := parent.FieldByIndexErr(field.Index)
+		if c.holds(val) == c.unless {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (c condition) holds(val reflect.Value) bool {
+	for !val.IsValid() || val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if !val.IsValid() || val.IsNil() {
+			return c.op == "==" && c.value == "" || c.op == "!=" && c.value != ""
+		}
+		val = val.Elem()
+	}
+
+	switch c.op {
+	case "==":
+		return fmt.Sprint(val.Interface()) == c.value
+	case "!=":
+		return fmt.Sprint(val.Interface()) != c.value
+	}
+	return !val.IsZero()
+}
+
+// parentKey is the context key of the struct holding the field being masked.
+type parentKey struct{}
+
+// withParent returns ctx carrying a pointer to the struct value parent, for maskers that receive the
+// parent struct.
+func withParent(ctx context.Context, parent reflect.Value) context.Context {
+	if !parent.CanAddr() || !parent.Addr().CanInterface() {
+		return ctx
+	}
+	return context.WithValue(ctx, parentKey{}, parent.Addr().Interface())
+}
+
+func createParentStructMaskFuncBuilder(
+	name string, masker func(interface{}, interface{}, ...string) error,
+) maskFuncBuilder {
+	return func(args ...string) maskFunc {
+		return func(ctx context.Context, ptr reflect.Value) error {
+			return masker(ptr.Interface(), ctx.Value(parentKey{}), args...)
+		}
+	}
+}
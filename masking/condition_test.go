@@ -0,0 +1,149 @@
+package masking_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Mask_WithConditions_MasksOnlyIfConditionsHold(t *testing.T) {
+	// arrange
+	type Account struct {
+		Country  string
+		Tier     *int
+		Verified bool
+		Number   string `mask:"X,showback=2,if=Country==US,unless=Verified"`
+		Note     string `mask:"*,if=Tier!=1"`
+	}
+	one, two := 1, 2
+	testCases := map[string]struct {
+		account        Account
+		expectedNumber string
+		expectedNote   string
+	}{
+		"all conditions hold": {
+			account:        Account{Country: "US", Tier: &two, Number: "1234", Note: "vip"},
+			expectedNumber: "XX34",
+			expectedNote:   "***",
+		},
+		"if does not hold": {
+			account:        Account{Country: "CA", Tier: &one, Number: "1234", Note: "vip"},
+			expectedNumber: "1234",
+			expectedNote:   "vip",
+		},
+		"unless holds": {
+			account:        Account{Country: "US", Verified: true, Number: "1234", Note: "vip"},
+			expectedNumber: "1234",
+			expectedNote:   "***",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// act
+			err := masking.Mask(&tc.account)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedNumber, tc.account.Number)
+			assert.Equal(t, tc.expectedNote, tc.account.Note)
+		})
+	}
+}
+
+func Test_Mask_WithConditionOnUnknownField_ReturnsError(t *testing.T) {
+	// arrange
+	type Account struct {
+		Number string `mask:"X,if=Region==US"`
+		region string
+	}
+	type Hidden struct {
+		Number string `mask:"X,unless=region"`
+		region string
+	}
+
+	// act
+	unknownErr := masking.Mask(&Account{Number: "1234"})
+	unexportedErr := masking.Mask(&Hidden{Number: "1234", region: "US"})
+
+	// assert
+	assert.EqualError(t, unknownErr, "condition: masking_test.Account has no field \"Region\"")
+	assert.EqualError(t, unexportedErr, "condition: field \"region\" of masking_test.Hidden is not exported")
+}
+
+func Test_MaskValue_WithCondition_ReturnsError(t *testing.T) {
+	// arrange
+	s := "1234"
+
+	// act
+	err := masking.MaskValue(&s, "X,if=Country==US")
+
+	// assert
+	assert.Error(t, err)
+	assert.Equal(t, "1234", s)
+}
+
+func Test_RegisterIn_WithConditionOnUnknownField_ReturnsError(t *testing.T) {
+	// arrange
+	type Account struct {
+		Country string
+	}
+	r := masking.NewRegistry()
+
+	// act
+	err := masking.ForType[Account]().Field("Country", "X,unless=Public").RegisterIn(r)
+
+	// assert
+	assert.Error(t, err)
+}
+
+func Test_PolicyValidate_WithConditionOnUnknownField_ReturnsError(t *testing.T) {
+	// arrange
+	type Account struct {
+		Country string
+	}
+	policy, err := masking.NewPolicy(map[string]string{
+		"github.com/dgravesa/go-mask/masking_test.Account.Country": "X,if=Region==US",
+	})
+	assert.NoError(t, err)
+
+	// act
+	err = policy.Validate(Account{})
+
+	// assert
+	assert.Error(t, err)
+}
+
+func Test_RegisterMasker_WithParentMasker_ReceivesParentStruct(t *testing.T) {
+	// arrange
+	type Account struct {
+		Country string
+		Number  string `mask:"country"`
+	}
+	r := masking.NewRegistry()
+	err := masking.RegisterMaskerIn(r, "country", func(v interface{}, parent interface{}, _ ...string) error {
+		account, ok := parent.(*Account)
+		if !ok {
+			return fmt.Errorf("unexpected parent %T", parent)
+		}
+		*v.(*string) = account.Country + "-XXXX"
+		return nil
+	})
+	assert.NoError(t, err)
+	type Wrapper struct {
+		Account Account
+	}
+	wrapper := Wrapper{Account: Account{Country: "US", Number: "1234"}}
+
+	// act
+	err = r.Mask(&wrapper)
+	value := "1234"
+	valueErr := r.MaskValue(&value, "country")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "US-XXXX", wrapper.Account.Number)
+	assert.Error(t, valueErr)
+}
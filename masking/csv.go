@@ -0,0 +1,160 @@
+package masking
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// CSVPolicy selects the masker for each column of CSV records.
+type CSVPolicy struct {
+	// Columns selects masker tags by the column names of the header record.
+	Columns KeyPolicy
+	// Indexes maps zero-based column indexes to masker tags. Indexes take precedence over Columns.
+	Indexes map[int]string
+}
+
+// CSVMasker streams CSV records from a reader to a writer, masking their columns.
+//
+// As with csv.Reader, the exported fields may be changed to customize the details before the first
+// record is masked.
+type CSVMasker struct {
+	// Comma is the field delimiter of both input and output. It is ',' by default; set it to '\t' for
+	// TSV.
+	Comma rune
+	// Comment, if not 0, is the comment character of the input. Comment lines are not written.
+	Comment rune
+	// LazyQuotes allows quotes to appear in unquoted fields and non-doubled quotes in quoted fields of
+	// the input.
+	LazyQuotes bool
+	// UseCRLF writes records with \r\n as the line terminator.
+	UseCRLF bool
+	// NoHeader indicates that the input has no header record, so columns are selected by Indexes only.
+	NoHeader bool
+
+	registry *Registry
+	policy   CSVPolicy
+	r        io.Reader
+	w        io.Writer
+	reader   *csv.Reader
+	writer   *csv.Writer
+	// maskTags holds the masker tags of masked columns by column index.
+	maskTags map[int]string
+	record   int
+}
+
+// NewCSVMasker returns a CSVMasker that reads records from r and writes masked records to w.
+//
+// Maskers are given by the same tags used in struct tags, such as "X,showfront=2" or the name of a
+// registered masker. The header record, if any, is written unmasked.
+func NewCSVMasker(r io.Reader, w io.Writer, policy CSVPolicy) *CSVMasker {
+	return DefaultRegistry.NewCSVMasker(r, w, policy)
+}
+
+// NewCSVMasker returns a CSVMasker that masks records using the maskers of r.
+func (r *Registry) NewCSVMasker(in io.Reader, out io.Writer, policy CSVPolicy) *CSVMasker {
+	return &CSVMasker{
+		Comma:    ',',
+		registry: r,
+		policy:   policy,
+		r:        in,
+		w:        out,
+	}
+}
+
+// MaskAll masks and writes all remaining records, then flushes the output.
+func (m *CSVMasker) MaskAll() error {
+	for {
+		err := m.MaskRecord()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	return m.Flush()
+}
+
+// MaskRecord masks and writes the next record. It returns io.EOF when there are no more records.
+// The first call writes the header record, if any.
+//
+// Output is buffered; call Flush once all records are written.
+func (m *CSVMasker) MaskRecord() error {
+	if m.reader == nil {
+		m.start()
+	}
+
+	record, err := m.reader.Read()
+	if err != nil {
+		return err
+	}
+	m.record++
+
+	if m.maskTags == nil {
+		var header []string
+		if !m.NoHeader {
+			header = record
+		}
+		if err := m.resolveMaskTags(header); err != nil {
+			return err
+		}
+		if header != nil {
+			return m.writer.Write(header)
+		}
+	}
+
+	for i, maskTag := range m.maskTags {
+		if i >= len(record) {
+			continue
+		}
+		if err := m.registry.MaskValue(&record[i], maskTag); err != nil {
+			return fmt.Errorf("mask: record %d, column %d: %w", m.record, i, err)
+		}
+	}
+	return m.writer.Write(record)
+}
+
+// Flush writes any buffered records to the output.
+func (m *CSVMasker) Flush() error {
+	if m.writer == nil {
+		return nil
+	}
+	m.writer.Flush()
+	return m.writer.Error()
+}
+
+func (m *CSVMasker) start() {
+	m.reader = csv.NewReader(m.r)
+	m.reader.Comma = m.Comma
+	m.reader.Comment = m.Comment
+	m.reader.LazyQuotes = m.LazyQuotes
+	m.reader.ReuseRecord = true
+
+	m.writer = csv.NewWriter(m.w)
+	m.writer.Comma = m.Comma
+	m.writer.UseCRLF = m.UseCRLF
+}
+
+// resolveMaskTags selects the masker tag of each column from the policy, and checks that the maskers
+// exist before any record is written.
+func (m *CSVMasker) resolveMaskTags(header []string) error {
+	m.maskTags = make(map[int]string)
+	for i, column := range header {
+		if maskTag, found := m.policy.Columns.Match(column); found {
+			m.maskTags[i] = maskTag
+		}
+	}
+	for i, maskTag := range m.policy.Indexes {
+		if i < 0 {
+			return fmt.Errorf("mask: invalid column index: %d", i)
+		}
+		m.maskTags[i] = maskTag
+	}
+
+	for i, maskTag := range m.maskTags {
+		if _, err := m.registry.getMaskFunc(maskTag); err != nil {
+			return fmt.Errorf("mask: column %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package masking_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dgravesa/go-mask/masking"
+)
+
+func Test_CSVMasker_WithColumnRules_MasksMatchingColumns(t *testing.T) {
+	// arrange
+	in := strings.NewReader("id,name,email,SSN\n" +
+		"1,Jane Smith,jane@example.com,123-45-6789\n" +
+		"2,\"Smith, John\",john@example.com,987-65-4321\n")
+	var out bytes.Buffer
+	policy := masking.CSVPolicy{
+		Columns: masking.KeyPolicy{
+			Rules: []masking.KeyRule{
+				{Key: "email", Mask: "X,showfront=2"},
+				{Key: "ssn", Mask: "X,showback=4"},
+			},
+			IgnoreCase: true,
+		},
+	}
+	masker := masking.NewCSVMasker(in, &out, policy)
+
+	// act
+	err := masker.MaskAll()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "id,name,email,SSN\n"+
+		"1,Jane Smith,jaXXXXXXXXXXXXXX,XXXXXXX6789\n"+
+		"2,\"Smith, John\",joXXXXXXXXXXXXXX,XXXXXXX4321\n", out.String())
+}
+
+func Test_CSVMasker_WithIndexRules_TakePrecedenceOverColumns(t *testing.T) {
+	// arrange
+	in := strings.NewReader("name,email\nJane,jane@example.com\n")
+	var out bytes.Buffer
+	policy := masking.CSVPolicy{
+		Columns: masking.KeyPolicy{
+			Rules: []masking.KeyRule{{Key: "email", Mask: "X"}},
+		},
+		Indexes: map[int]string{0: "*", 1: "x"},
+	}
+	masker := masking.NewCSVMasker(in, &out, policy)
+
+	// act
+	err := masker.MaskAll()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "name,email\n****,xxxxxxxxxxxxxxxx\n", out.String())
+}
+
+func Test_CSVMasker_WithHeaderlessTSV_MasksByIndex(t *testing.T) {
+	// arrange
+	in := strings.NewReader("# exported accounts\nacct-1\t4111111111111111\nacct-2\t5500000000000004\n")
+	var out bytes.Buffer
+	policy := masking.CSVPolicy{
+		Indexes: map[int]string{1: "X,showback=4"},
+	}
+	masker := masking.NewCSVMasker(in, &out, policy)
+	masker.Comma = '\t'
+	masker.Comment = '#'
+	masker.NoHeader = true
+
+	// act
+	err := masker.MaskAll()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "acct-1\tXXXXXXXXXXXX1111\nacct-2\tXXXXXXXXXXXX0004\n", out.String())
+}
+
+func Test_CSVMasker_MaskRecord_StreamsOneRecordAtATime(t *testing.T) {
+	// arrange
+	in := strings.NewReader("password\nhunter2\nswordfish\n")
+	var out bytes.Buffer
+	masker := masking.NewCSVMasker(in, &out, masking.CSVPolicy{Columns: masking.DefaultKeyPolicy})
+
+	// act
+	headerErr := masker.MaskRecord()
+	firstErr := masker.MaskRecord()
+	flushErr := masker.Flush()
+	afterFirst := out.String()
+	secondErr := masker.MaskRecord()
+	endErr := masker.MaskRecord()
+	masker.Flush()
+
+	// assert
+	assert.NoError(t, headerErr)
+	assert.NoError(t, firstErr)
+	assert.NoError(t, flushErr)
+	assert.Equal(t, "password\n*******\n", afterFirst)
+	assert.NoError(t, secondErr)
+	assert.Equal(t, io.EOF, endErr)
+	assert.Equal(t, "password\n*******\n*********\n", out.String())
+}
+
+func Test_CSVMasker_WithUnknownMasker_ReturnsErrorBeforeWriting(t *testing.T) {
+	// arrange
+	in := strings.NewReader("email\njane@example.com\n")
+	var out bytes.Buffer
+	policy := masking.CSVPolicy{
+		Indexes: map[int]string{0: "unregistered"},
+	}
+	masker := masking.NewCSVMasker(in, &out, policy)
+
+	// act
+	err := masker.MaskAll()
+
+	// assert
+	assert.Error(t, err)
+	assert.Equal(t, "", out.String())
+}
+
+func Test_CSVMasker_WithMalformedInput_ReturnsError(t *testing.T) {
+	// arrange
+	in := strings.NewReader("name,email\n\"Jane,jane@example.com\n")
+	var out bytes.Buffer
+	masker := masking.NewCSVMasker(in, &out, masking.CSVPolicy{})
+
+	// act
+	err := masker.MaskAll()
+
+	// assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,188 @@
+package masking
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Plan is the static masking plan of a struct type, as returned by Describe. A Plan describes how
+// masking would apply to values of the type without masking any value, and may be marshaled to JSON.
+type Plan struct {
+	// Type is the name of the described type.
+	Type string `json:"type"`
+	// Masker is "self" if the type is a SelfMasker or MaskedValuer, or "type:T" if it has a type masker,
+	// in which case values of the type are masked as a whole and Fields is empty.
+	Masker string `json:"masker,omitempty"`
+	// Fields describes every exported field reachable by Mask or DeepMask, in declaration order.
+	Fields []FieldPlan `json:"fields"`
+	// Warnings lists likely mistakes in the masking of the type, each prefixed by a field path, such as
+	// an untagged field named Password or a tag referring to an unknown masker.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// FieldPlan describes the masking of a single field.
+type FieldPlan struct {
+	// Path is the field path of the field, such as "Orders[].Card.Number", where "[]" stands for any
+	// slice or array index.
+	Path string `json:"path"`
+	// Type is the Go type of the field, such as "*string".
+	Type string `json:"type"`
+	// Tag is the masker tag given by the struct tags of the field, if any.
+	Tag string `json:"tag,omitempty"`
+	// Masker is the masker tag applied to the field, whether given by its struct tags, a field rule, a
+	// policy or a class policy, or "self" for a SelfMasker or MaskedValuer, or "type:T" for the type
+	// masker of type T. Fields without a masker are left as is, though their nested fields may be masked.
+	Masker string `json:"masker,omitempty"`
+	// Mask reports whether Mask reaches the field, which it does not behind pointers and slices.
+	Mask bool `json:"mask"`
+	// DeepMask reports whether DeepMask reaches the field.
+	DeepMask bool `json:"deepMask"`
+}
+
+// Describe returns the static masking plan of struct type T using the maskers and policies of
+// DefaultRegistry, for documentation and security reviews of types.
+//
+// Fields within maps and interfaces are not described, since they are known only from values. Fields
+// of a type nested within itself, such as through a pointer, are described only at the outermost
+// occurrence of the type.
+func Describe[T any]() (*Plan, error) {
+	return DescribeIn[T](DefaultRegistry)
+}
+
+// DescribeIn returns the static masking plan of struct type T using the maskers and policies of r.
+//
+// See Describe for details.
+func DescribeIn[T any](r *Registry) (*Plan, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("describe: %s is not a struct type", t)
+	}
+
+	plan := &Plan{Type: t.String(), Masker: r.valueMasker(t), Fields: []FieldPlan{}}
+	if plan.Masker == "" {
+		r.describe(plan, t, "", true, map[reflect.Type]bool{})
+	}
+	return plan, nil
+}
+
+// describe adds the fields reachable within a value of type t at path to plan. shallow reports whether
+// Mask reaches the value.
+func (r *Registry) describe(
+	plan *Plan, t reflect.Type, path string, shallow bool, seen map[reflect.Type]bool,
+) {
+	switch t.Kind() {
+	case reflect.Pointer:
+		r.describe(plan, t.Elem(), path, false, seen)
+
+	case reflect.Slice, reflect.Array:
+		itemPath := path + "[]"
+		itemShallow := shallow && t.Kind() == reflect.Array
+		if masker := r.valueMasker(t.Elem()); masker != "" {
+			// items are masked as a whole in place of traversal
+			plan.Fields = append(plan.Fields, FieldPlan{
+				Path:     itemPath,
+				Type:     t.Elem().String(),
+				Masker:   masker,
+				Mask:     itemShallow && t.Elem().Kind() != reflect.Pointer,
+				DeepMask: true,
+			})
+			return
+		}
+		r.describe(plan, t.Elem(), itemPath, itemShallow, seen)
+
+	case reflect.Struct:
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			r.describeField(plan, t, i, path, shallow, seen)
+		}
+	}
+}
+
+// describeField adds the ith field of struct type t, and the fields reachable within it, to plan.
+func (r *Registry) describeField(
+	plan *Plan, t reflect.Type, i int, path string, shallow bool, seen map[reflect.Type]bool,
+) {
+	field := t.Field(i)
+	fieldPath := joinKeyPath(path, field.Name)
+	tag, _ := structMaskTag(field, r.tagKeys, "")
+
+	if !field.IsExported() {
+		if tag != "" {
+			plan.warn(fieldPath, "mask tag of unexported field is ignored unless masking unexported fields")
+		}
+		return
+	}
+
+	fieldPlan := FieldPlan{
+		Path:     fieldPath,
+		Type:     field.Type.String(),
+		Tag:      tag,
+		Mask:     shallow && field.Type.Kind() != reflect.Pointer,
+		DeepMask: true,
+	}
+	maskTag, _, err := r.fieldMaskTag(t, i, r.tagKeys, "")
+	if err == nil && maskTag != "" {
+		var fm fieldMasker
+		if fm, err = r.getFieldMasker(maskTag); err == nil {
+			err = fm.conditions.validate(t)
+		}
+	}
+	if err != nil {
+		plan.Fields = append(plan.Fields, fieldPlan)
+		plan.warn(fieldPath, err.Error())
+		return
+	}
+
+	if maskTag != "" {
+		fieldPlan.Masker = maskTag
+		plan.Fields = append(plan.Fields, fieldPlan)
+		if !fieldPlan.Mask {
+			plan.warn(fieldPath, "masked by DeepMask but not by Mask")
+		}
+		return
+	}
+
+	fieldPlan.Masker = r.valueMasker(field.Type)
+	plan.Fields = append(plan.Fields, fieldPlan)
+	if fieldPlan.Masker != "" {
+		return
+	}
+	if _, sensitive := DefaultKeyPolicy.Match(field.Name); sensitive && !holdsStruct(field.Type) {
+		plan.warn(fieldPath, "untagged field with sensitive name is not masked")
+	}
+	r.describe(plan, field.Type, fieldPath, fieldPlan.Mask, seen)
+}
+
+// valueMasker returns the masker applied in place of traversal to values of type t, or behind a
+// pointer of type t, if any.
+func (r *Registry) valueMasker(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if _, found := r.typeMaskers[t]; found {
+		return "type:" + t.String()
+	}
+	ptrType := reflect.PointerTo(t)
+	if ptrType.Implements(selfMaskerType) || ptrType.Implements(maskedValuerType) {
+		return "self"
+	}
+	return ""
+}
+
+// holdsStruct reports whether values of type t are structs, or pointers, slices or arrays of structs,
+// whose fields are described separately.
+func holdsStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+func (p *Plan) warn(fieldPath, message string) {
+	p.Warnings = append(p.Warnings, fmt.Sprintf("%s: %s", fieldPath, message))
+}
@@ -0,0 +1,149 @@
+package masking_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Describe_WithStruct_ReturnsPlanOfReachableFields(t *testing.T) {
+	// arrange
+	type Address struct {
+		Street string `mask:"X"`
+		City   string
+	}
+	type User struct {
+		Email    string `mask:"X,showfront=2"`
+		Password string
+		Card     CardNumber
+		Address  Address
+		Previous []Address
+		Manager  *User
+		Token    masking.Secret[string]
+		Nickname *string `mask:"*"`
+		Country  string
+		Phone    string `mask:"X,if=Region==US"`
+		Metadata map[string]string
+		internal string `mask:"X"`
+	}
+
+	// act
+	plan, err := masking.Describe[User]()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "masking_test.User", plan.Type)
+	assert.Equal(t, []masking.FieldPlan{
+		{Path: "Email", Type: "string", Tag: "X,showfront=2", Masker: "X,showfront=2", Mask: true, DeepMask: true},
+		{Path: "Password", Type: "string", Mask: true, DeepMask: true},
+		{Path: "Card", Type: "masking_test.CardNumber", Masker: "self", Mask: true, DeepMask: true},
+		{Path: "Address", Type: "masking_test.Address", Mask: true, DeepMask: true},
+		{Path: "Address.Street", Type: "string", Tag: "X", Masker: "X", Mask: true, DeepMask: true},
+		{Path: "Address.City", Type: "string", Mask: true, DeepMask: true},
+		{Path: "Previous", Type: "[]masking_test.Address", Mask: true, DeepMask: true},
+		{Path: "Previous[].Street", Type: "string", Tag: "X", Masker: "X", DeepMask: true},
+		{Path: "Previous[].City", Type: "string", DeepMask: true},
+		{Path: "Manager", Type: "*masking_test.User", DeepMask: true},
+		{Path: "Token", Type: "masking.Secret[string]", Mask: true, DeepMask: true},
+		{Path: "Nickname", Type: "*string", Tag: "*", Masker: "*", DeepMask: true},
+		{Path: "Country", Type: "string", Mask: true, DeepMask: true},
+		{Path: "Phone", Type: "string", Tag: "X,if=Region==US", Mask: true, DeepMask: true},
+		{Path: "Metadata", Type: "map[string]string", Mask: true, DeepMask: true},
+	}, plan.Fields)
+	assert.Equal(t, []string{
+		"Password: untagged field with sensitive name is not masked",
+		"Previous[].Street: masked by DeepMask but not by Mask",
+		"Nickname: masked by DeepMask but not by Mask",
+		"Phone: condition: masking_test.User has no field \"Region\"",
+		"internal: mask tag of unexported field is ignored unless masking unexported fields",
+	}, plan.Warnings)
+}
+
+func Test_DescribeIn_WithFieldRulesAndTypeMaskers_ResolvesMaskers(t *testing.T) {
+	// arrange
+	type Address struct {
+		Street string `mask:"X"`
+	}
+	type Account struct {
+		Id       string
+		Password string
+		Address  Address
+	}
+	r := masking.NewRegistry()
+	assert.NoError(t, masking.ForType[Account]().Field("Password", "*").RegisterIn(r))
+	assert.NoError(t, masking.RegisterTypeMaskerIn(r, func(a *Address) error { return nil }))
+
+	// act
+	plan, err := masking.DescribeIn[Account](r)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []masking.FieldPlan{
+		{Path: "Id", Type: "string", Mask: true, DeepMask: true},
+		{Path: "Password", Type: "string", Masker: "*", Mask: true, DeepMask: true},
+		{
+			Path: "Address", Type: "masking_test.Address",
+			Masker: "type:masking_test.Address", Mask: true, DeepMask: true,
+		},
+	}, plan.Fields)
+	assert.Empty(t, plan.Warnings)
+}
+
+func Test_Describe_WithUnknownMasker_ReturnsWarning(t *testing.T) {
+	// arrange
+	type Account struct {
+		Id string `mask:"nosuchmasker"`
+	}
+
+	// act
+	plan, err := masking.Describe[Account]()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, plan.Warnings, 1)
+	assert.Contains(t, plan.Warnings[0], "Id: ")
+}
+
+func Test_Describe_WithSelfMaskerType_ReturnsPlanWithoutFields(t *testing.T) {
+	// act
+	plan, err := masking.Describe[CardNumber]()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "self", plan.Masker)
+	assert.Empty(t, plan.Fields)
+}
+
+func Test_Describe_WithNonStructType_ReturnsError(t *testing.T) {
+	// act
+	plan, err := masking.Describe[*CardNumber]()
+
+	// assert
+	assert.Error(t, err)
+	assert.Nil(t, plan)
+}
+
+func Test_Describe_MarshaledToJSON_ReturnsPlan(t *testing.T) {
+	// arrange
+	type Address struct {
+		Street string `mask:"X"`
+		City   string
+	}
+	plan, err := masking.Describe[Address]()
+	assert.NoError(t, err)
+
+	// act
+	data, err := json.Marshal(plan)
+
+	// assert
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "masking_test.Address",
+		"fields": [
+			{"path": "Street", "type": "string", "tag": "X", "masker": "X", "mask": true, "deepMask": true},
+			{"path": "City", "type": "string", "mask": true, "deepMask": true}
+		]
+	}`, string(data))
+}
@@ -0,0 +1,313 @@
+package masking
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Match is a span of sensitive text found by a Detector.
+type Match struct {
+	// Start and End are the byte offsets of the span within the text.
+	Start, End int
+	// Kind names the type of value found, such as "email".
+	Kind string
+	// Mask is the masker tag applied to the span.
+	Mask string
+}
+
+// Detector finds sensitive values embedded in free text.
+type Detector interface {
+	Detect(s string) []Match
+}
+
+// KindDetector is implemented by detectors that report the kinds of values they find, so that the
+// "detect" masker can reject kinds that no detector finds.
+type KindDetector interface {
+	Detector
+	Kinds() []string
+}
+
+// RegexDetector is a Detector that finds the matches of a regular expression, optionally filtered by a
+// validator.
+type RegexDetector struct {
+	Kind    string
+	Pattern *regexp.Regexp
+	// Validate, if not nil, reports whether a match of Pattern is a value of Kind.
+	Validate func(match string) bool
+	// Mask is the masker tag applied to matches.
+	Mask string
+}
+
+// Detect returns the valid matches of d.Pattern in s.
+func (d *RegexDetector) Detect(s string) []Match {
+	var matches []Match
+	for _, loc := range d.Pattern.FindAllStringIndex(s, -1) {
+		if d.Validate != nil && !d.Validate(s[loc[0]:loc[1]]) {
+			continue
+		}
+		matches = append(matches, Match{Start: loc[0], End: loc[1], Kind: d.Kind, Mask: d.Mask})
+	}
+	return matches
+}
+
+// Kinds returns d.Kind.
+func (d *RegexDetector) Kinds() []string {
+	return []string{d.Kind}
+}
+
+// The built-in detectors. Their Mask may be changed on a copy, such as:
+//
+//	ssn := *masking.SSNDetector
+//	ssn.Mask = "*"
+var (
+	// EmailDetector finds email addresses.
+	EmailDetector = &RegexDetector{
+		Kind:    "email",
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+		Mask:    "X,alphanumeric",
+	}
+	// CardNumberDetector finds payment card numbers of 13 to 19 digits, optionally separated by spaces
+	// or dashes, that pass the Luhn checksum.
+	CardNumberDetector = &RegexDetector{
+		Kind:     "card",
+		Pattern:  regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		Validate: LuhnValid,
+		Mask:     "X,showback=4,alphanumeric",
+	}
+	// SSNDetector finds US social security numbers written as AAA-GG-SSSS with valid area, group and
+	// serial numbers.
+	SSNDetector = &RegexDetector{
+		Kind:     "ssn",
+		Pattern:  regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+		Validate: ValidSSN,
+		Mask:     "X,showback=4,alphanumeric",
+	}
+	// IPDetector finds IPv4 addresses, and IPv6 addresses of at least three groups.
+	IPDetector = &RegexDetector{
+		Kind:     "ip",
+		Pattern:  regexp.MustCompile(`(?i)\b(?:\d{1,3}\.){3}\d{1,3}\b|(?:[0-9a-f]{0,4}:){2,7}[0-9a-f]{0,4}`),
+		Validate: validIP,
+		Mask:     "X,alphanumeric",
+	}
+	// PhoneDetector finds phone numbers of 10 digits, such as (555) 123-4567 or 555.123.4567, with an
+	// optional international prefix.
+	PhoneDetector = &RegexDetector{
+		Kind:    "phone",
+		Pattern: regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{3}\)[ .-]?|\b\d{3}[ .-]?)\d{3}[ .-]?\d{4}\b`),
+		Mask:    "X,showback=4,alphanumeric",
+	}
+)
+
+// DefaultDetectors returns the built-in detectors.
+func DefaultDetectors() []Detector {
+	return []Detector{EmailDetector, CardNumberDetector, SSNDetector, IPDetector, PhoneDetector}
+}
+
+// LuhnValid reports whether s, ignoring spaces and dashes, is a number of 13 to 19 digits that passes
+// the Luhn checksum.
+func LuhnValid(s string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(s)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// ValidSSN reports whether s is a social security number of the form AAA-GG-SSSS that has not been
+// ruled out by the issuance rules: the area is not 000, 666 or 900-999, the group is not 00 and the
+// serial is not 0000.
+func ValidSSN(s string) bool {
+	parts := strings.Split(s, "-")
+	if len(parts) != 3 || len(parts[0]) != 3 || len(parts[1]) != 2 || len(parts[2]) != 4 {
+		return false
+	}
+	for _, part := range parts {
+		if strings.Trim(part, "0123456789") != "" {
+			return false
+		}
+	}
+
+	area, group, serial := parts[0], parts[1], parts[2]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+func validIP(s string) bool {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return false
+	}
+	if !strings.Contains(s, ":") {
+		return true
+	}
+
+	groups := 0
+	for _, group := range strings.Split(s, ":") {
+		if group != "" {
+			groups++
+		}
+	}
+	return groups >= 3
+}
+
+// Detect returns the non-overlapping spans of s found by detectors, in order. Where spans overlap, the
+// span starting first is kept, or the longest if they start together.
+//
+// If no detectors are given, the detectors of DefaultRegistry are used.
+func Detect(s string, detectors ...Detector) []Match {
+	return DefaultRegistry.Detect(s, detectors...)
+}
+
+// Detect returns the non-overlapping spans of s found by detectors, or by the detectors of r if none
+// are given.
+func (r *Registry) Detect(s string, detectors ...Detector) []Match {
+	if len(detectors) == 0 {
+		detectors = r.detectors
+	}
+
+	var found []Match
+	for _, d := range detectors {
+		found = append(found, d.Detect(s)...)
+	}
+	return resolveMatches(found)
+}
+
+// resolveMatches sorts matches and removes those overlapping an earlier or longer match.
+func resolveMatches(found []Match) []Match {
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Start != found[j].Start {
+			return found[i].Start < found[j].Start
+		}
+		return found[i].End > found[j].End
+	})
+
+	var matches []Match
+	end := 0
+	for _, m := range found {
+		if m.Start < end || m.Start >= m.End {
+			continue
+		}
+		matches = append(matches, m)
+		end = m.End
+	}
+	return matches
+}
+
+// MaskText returns s with the sensitive values found by detectors masked, leaving the surrounding
+// text intact. Each span is masked by the masker tag of its match.
+//
+// If no detectors are given, the detectors of DefaultRegistry are used, which are DefaultDetectors
+// unless others are registered with RegisterDetector.
+func MaskText(s string, detectors ...Detector) (string, error) {
+	return DefaultRegistry.MaskText(s, detectors...)
+}
+
+// MaskText returns s with the sensitive values found by detectors masked using the maskers of r, or
+// by the detectors of r if none are given.
+func (r *Registry) MaskText(s string, detectors ...Detector) (string, error) {
+	return r.maskMatches(s, r.Detect(s, detectors...))
+}
+
+func (r *Registry) maskMatches(s string, matches []Match) (string, error) {
+	if len(matches) == 0 {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		span := s[m.Start:m.End]
+		if err := r.MaskValue(&span, m.Mask); err != nil {
+			return "", fmt.Errorf("%s: %w", m.Kind, err)
+		}
+		sb.WriteString(s[last:m.Start])
+		sb.WriteString(span)
+		last = m.End
+	}
+	sb.WriteString(s[last:])
+	return sb.String(), nil
+}
+
+// RegisterDetector adds d to the detectors of DefaultRegistry.
+func RegisterDetector(d Detector) {
+	DefaultRegistry.RegisterDetector(d)
+}
+
+// RegisterDetector adds d to the detectors of r, which are used by the "detect" masker and by
+// MaskText when no detectors are given.
+func (r *Registry) RegisterDetector(d Detector) {
+	r.detectors = append(r.detectors, d)
+}
+
+// detectMaskFuncBuilder returns the builder of the "detect" masker, which masks the values found in
+// strings by the detectors of r. Arguments restrict the detectors to the given kinds, such as
+// `mask:"detect,email,phone"`. An error is returned for a kind that no detector of r finds, unless a
+// detector does not implement KindDetector.
+func (r *Registry) detectMaskFuncBuilder() maskFuncBuilder {
+	return createStringMaskFuncBuilder("detect", func(s *string, kinds ...string) error {
+		if kind, found := r.unknownDetectorKind(kinds); found {
+			return fmt.Errorf("detect: unknown kind \"%s\"", kind)
+		}
+
+		var found []Match
+		for _, d := range r.detectors {
+			for _, m := range d.Detect(*s) {
+				if len(kinds) == 0 || containsString(kinds, m.Kind) {
+					found = append(found, m)
+				}
+			}
+		}
+
+		masked, err := r.maskMatches(*s, resolveMatches(found))
+		if err != nil {
+			return err
+		}
+		*s = masked
+		return nil
+	})
+}
+
+// unknownDetectorKind returns the first of kinds that no detector of r finds, if every detector of r
+// reports its kinds.
+func (r *Registry) unknownDetectorKind(kinds []string) (string, bool) {
+	var known []string
+	for _, d := range r.detectors {
+		kd, ok := d.(KindDetector)
+		if !ok {
+			return "", false
+		}
+		known = append(known, kd.Kinds()...)
+	}
+	for _, kind := range kinds {
+		if !containsString(known, kind) {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
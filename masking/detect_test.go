@@ -0,0 +1,235 @@
+package masking_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dgravesa/go-mask/masking"
+)
+
+func Test_MaskText_WithDefaultDetectors_MasksOnlyMatchedSpans(t *testing.T) {
+	// arrange
+	testCases := map[string]struct {
+		text     string
+		expected string
+	}{
+		"email": {
+			text:     "Contact jane.doe@example.com for access.",
+			expected: "Contact XXXX.XXX@XXXXXXX.XXX for access.",
+		},
+		"card": {
+			text:     "Card 4111 1111 1111 1111 was declined.",
+			expected: "Card XXXX XXXX XXXX 1111 was declined.",
+		},
+		"ssn": {
+			text:     "SSN on file: 123-45-6789.",
+			expected: "SSN on file: XXX-XX-6789.",
+		},
+		"ipv4": {
+			text:     "Login from 192.168.10.4 failed",
+			expected: "Login from XXX.XXX.XX.X failed",
+		},
+		"ipv6": {
+			text:     "Login from 2001:db8::8a2e:370:7334 failed",
+			expected: "Login from XXXX:XXX::XXXX:XXX:XXXX failed",
+		},
+		"phone": {
+			text:     "Call (555) 123-4567 or +1 555.987.6543 tomorrow",
+			expected: "Call (XXX) XXX-4567 or +X XXX.XXX.6543 tomorrow",
+		},
+		"no sensitive values": {
+			text:     "Order 12345 shipped at 10:30:00 on 2024-01-15.",
+			expected: "Order 12345 shipped at 10:30:00 on 2024-01-15.",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// act
+			masked, err := masking.MaskText(tc.text)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, masked)
+		})
+	}
+}
+
+func Test_MaskText_WithInvalidChecksums_LeavesTextUnmasked(t *testing.T) {
+	// arrange
+	text := "Ticket 4111 1111 1111 1112 references SSN 666-12-3456 and 123-00-4567."
+
+	// act
+	masked, err := masking.MaskText(text, masking.CardNumberDetector, masking.SSNDetector)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, text, masked)
+}
+
+func Test_MaskText_WithCustomDetector_UsesItsMask(t *testing.T) {
+	// arrange
+	employeeID := &masking.RegexDetector{
+		Kind:    "employee",
+		Pattern: regexp.MustCompile(`\bEMP-\d{6}\b`),
+		Mask:    "*,showfront=4",
+	}
+
+	// act
+	masked, err := masking.MaskText("Assigned to EMP-123456 (jane@example.com)", employeeID)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Assigned to EMP-****** (jane@example.com)", masked)
+}
+
+type upperDetector struct{}
+
+func (upperDetector) Detect(s string) []masking.Match {
+	var matches []masking.Match
+	for _, loc := range regexp.MustCompile(`[A-Z]{3,}`).FindAllStringIndex(s, -1) {
+		matches = append(matches, masking.Match{Start: loc[0], End: loc[1], Kind: "upper", Mask: "x"})
+	}
+	return matches
+}
+
+func Test_Detect_WithOverlappingMatches_KeepsEarliestAndLongest(t *testing.T) {
+	// arrange
+	text := "id 4111-1111-1111-1111 SSN"
+
+	// act
+	matches := masking.Detect(text, masking.PhoneDetector, masking.CardNumberDetector, upperDetector{})
+
+	// assert
+	assert.Equal(t, []masking.Match{
+		{Start: 3, End: 22, Kind: "card", Mask: "X,showback=4,alphanumeric"},
+		{Start: 23, End: 26, Kind: "upper", Mask: "x"},
+	}, matches)
+}
+
+func Test_MaskText_WithUnknownMasker_ReturnsError(t *testing.T) {
+	// arrange
+	detector := &masking.RegexDetector{
+		Kind:    "word",
+		Pattern: regexp.MustCompile(`secret`),
+		Mask:    "unregistered",
+	}
+
+	// act
+	_, err := masking.MaskText("a secret", detector)
+
+	// assert
+	assert.Error(t, err)
+}
+
+func Test_Mask_WithDetectTag_MasksDetectedValues(t *testing.T) {
+	// arrange
+	type Ticket struct {
+		Subject     string
+		Description string `mask:"detect"`
+		Notes       string `mask:"detect,email"`
+	}
+	ticket := Ticket{
+		Subject:     "Refund for jane@example.com",
+		Description: "Customer jane@example.com called from 555-123-4567.",
+		Notes:       "Reach jane@example.com or 555-123-4567.",
+	}
+
+	// act
+	err := masking.Mask(&ticket)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, Ticket{
+		Subject:     "Refund for jane@example.com",
+		Description: "Customer XXXX@XXXXXXX.XXX called from XXX-XXX-4567.",
+		Notes:       "Reach XXXX@XXXXXXX.XXX or 555-123-4567.",
+	}, ticket)
+}
+
+func Test_RegisterDetector_AddsToDetectTag(t *testing.T) {
+	// arrange
+	type Ticket struct {
+		Subject     string
+		Description string `mask:"detect"`
+		Notes       string `mask:"detect,email"`
+	}
+	r := masking.NewRegistry()
+	r.RegisterDetector(&masking.RegexDetector{
+		Kind:    "order",
+		Pattern: regexp.MustCompile(`\bORD-\d+\b`),
+		Mask:    "X,showfront=4",
+	})
+	ticket := Ticket{Description: "Order ORD-98765 for jane@example.com"}
+
+	// act
+	err := r.Mask(&ticket)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Order ORD-XXXXX for XXXX@XXXXXXX.XXX", ticket.Description)
+}
+
+func Test_LuhnValid_ChecksDigitsAndChecksum(t *testing.T) {
+	// arrange
+	testCases := map[string]bool{
+		"4111111111111111":      true,
+		"4111-1111-1111-1111":   true,
+		"5500 0000 0000 0004":   true,
+		"4111111111111112":      false,
+		"411111111111":          false,
+		"4111a11111111111":      false,
+		strings.Repeat("0", 20): false,
+	}
+
+	for number, expected := range testCases {
+		t.Run(number, func(t *testing.T) {
+			// act
+			valid := masking.LuhnValid(number)
+
+			// assert
+			assert.Equal(t, expected, valid)
+		})
+	}
+}
+
+func Test_ValidSSN_AppliesIssuanceRules(t *testing.T) {
+	// arrange
+	testCases := map[string]bool{
+		"123-45-6789": true,
+		"000-12-3456": false,
+		"666-12-3456": false,
+		"900-12-3456": false,
+		"123-00-4567": false,
+		"123-45-0000": false,
+		"123456789":   false,
+		"12a-45-6789": false,
+	}
+
+	for ssn, expected := range testCases {
+		t.Run(ssn, func(t *testing.T) {
+			// act
+			valid := masking.ValidSSN(ssn)
+
+			// assert
+			assert.Equal(t, expected, valid)
+		})
+	}
+}
+
+func Test_Mask_WithDetectTagOfUnknownKind_ReturnsError(t *testing.T) {
+	// arrange
+	v := struct {
+		Notes string `mask:"detect,emial"`
+	}{Notes: "Reach jane@example.com"}
+
+	// act
+	err := masking.Mask(&v)
+
+	// assert
+	assert.EqualError(t, err, `mask: Notes: detect: unknown kind "emial"`)
+	assert.Equal(t, "Reach jane@example.com", v.Notes)
+}
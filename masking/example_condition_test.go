@@ -0,0 +1,29 @@
+package masking_test
+
+import (
+	"fmt"
+
+	"github.com/dgravesa/go-mask/masking"
+)
+
+type Contact struct {
+	Country string
+	Public  bool
+	Phone   string `mask:"X,showback=4,if=Country==US"`
+	Email   string `mask:"*,unless=Public"`
+}
+
+func ExampleMask_conditions() {
+	contacts := []Contact{
+		{Country: "US", Public: false, Phone: "555-123-4567", Email: "jane@example.com"},
+		{Country: "CA", Public: true, Phone: "555-765-4321", Email: "info@example.com"},
+	}
+
+	for _, contact := range contacts {
+		masking.Mask(&contact)
+		fmt.Printf("%s, %s\n", contact.Phone, contact.Email)
+	}
+	// Output:
+	// XXXXXXXX4567, ****************
+	// 555-765-4321, info@example.com
+}
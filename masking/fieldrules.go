@@ -0,0 +1,89 @@
+package masking
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FieldRules declares masker tags for fields of a struct type T without modifying its struct tags.
+//
+// FieldRules are created with ForType and take effect once registered:
+//
+//	err := masking.ForType[pb.User]().
+//		Field("Email", "*").
+//		Field("Ssn", "X,showback=4").
+//		Register()
+type FieldRules[T any] struct {
+	t      reflect.Type
+	fields map[string]string
+	err    error
+}
+
+// ForType returns an empty set of field rules for struct type T.
+func ForType[T any]() *FieldRules[T] {
+	fr := &FieldRules[T]{
+		t:      reflect.TypeOf((*T)(nil)).Elem(),
+		fields: make(map[string]string),
+	}
+	if fr.t.Kind() != reflect.Struct {
+		fr.err = fmt.Errorf("field rules: %s is not a struct type", fr.t)
+	}
+	return fr
+}
+
+// Field applies masking with maskTag to the named field of T.
+//
+// The field must be an exported field declared directly on T. Any problem with the field is reported
+// when the rules are registered.
+func (fr *FieldRules[T]) Field(name, maskTag string) *FieldRules[T] {
+	if fr.err != nil {
+		return fr
+	}
+
+	field, found := fr.t.FieldByName(name)
+	switch {
+	case !found || len(field.Index) != 1:
+		fr.err = fmt.Errorf("field rules: %s has no field \"%s\"", fr.t, name)
+	case !field.IsExported():
+		fr.err = fmt.Errorf("field rules: field \"%s\" of %s is not exported", name, fr.t)
+	case maskTag == "":
+		fr.err = fmt.Errorf("field rules: empty mask tag for field \"%s\" of %s", name, fr.t)
+	default:
+		fr.fields[name] = maskTag
+	}
+
+	return fr
+}
+
+// Register adds the field rules to DefaultRegistry.
+func (fr *FieldRules[T]) Register() error {
+	return fr.RegisterIn(DefaultRegistry)
+}
+
+// RegisterIn adds the field rules to r.
+//
+// An error is returned if any field declared by the rules is invalid or refers to a masker that is
+// not registered with r. Struct tags on fields of T take precedence over the rules.
+func (fr *FieldRules[T]) RegisterIn(r *Registry) error {
+	if fr.err != nil {
+		return fr.err
+	}
+
+	for _, maskTag := range fr.fields {
+		fieldMasker, err := r.getFieldMasker(maskTag)
+		if err == nil {
+			err = fieldMasker.conditions.validate(fr.t)
+		}
+		if err != nil {
+			return fmt.Errorf("field rules: %w", err)
+		}
+	}
+
+	if r.fieldRules[fr.t] == nil {
+		r.fieldRules[fr.t] = make(map[string]string)
+	}
+	for name, maskTag := range fr.fields {
+		r.fieldRules[fr.t][name] = maskTag
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package masking_test
+
+import (
+	"testing"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ForType_WithFieldRules_MasksUntaggedFields(t *testing.T) {
+	// arrange
+	type Inner struct {
+		Ssn string
+	}
+	type Outer struct {
+		Email string
+		Inner Inner
+	}
+	r := masking.NewRegistry()
+	err := masking.ForType[Outer]().Field("Email", "*").RegisterIn(r)
+	assert.NoError(t, err)
+	err = masking.ForType[Inner]().Field("Ssn", "X,showback=4").RegisterIn(r)
+	assert.NoError(t, err)
+	s := Outer{
+		Email: "jane@example.com",
+		Inner: Inner{
+			Ssn: "123-45-6789",
+		},
+	}
+
+	// act
+	err = r.Mask(&s)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, Outer{
+		Email: "****************",
+		Inner: Inner{
+			Ssn: "XXXXXXX6789",
+		},
+	}, s)
+}
+
+func Test_ForType_WithTaggedField_PrefersTag(t *testing.T) {
+	// arrange
+	type S struct {
+		Secret string `mask:"*"`
+	}
+	r := masking.NewRegistry()
+	err := masking.ForType[S]().Field("Secret", "X").RegisterIn(r)
+	assert.NoError(t, err)
+	s := S{
+		Secret: "hunter2",
+	}
+
+	// act
+	err = r.Mask(&s)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "*******", s.Secret)
+}
+
+func Test_ForType_WithInvalidRules_ReturnsError(t *testing.T) {
+	// arrange
+	type S struct {
+		Secret string
+		hidden string
+	}
+	type TestCase struct {
+		Name     string
+		Register func(r *masking.Registry) error
+	}
+	testCases := []TestCase{
+		{
+			Name: "non-struct type",
+			Register: func(r *masking.Registry) error {
+				return masking.ForType[string]().Field("Secret", "X").RegisterIn(r)
+			},
+		},
+		{
+			Name: "unknown field",
+			Register: func(r *masking.Registry) error {
+				return masking.ForType[S]().Field("Unknown", "X").RegisterIn(r)
+			},
+		},
+		{
+			Name: "unexported field",
+			Register: func(r *masking.Registry) error {
+				return masking.ForType[S]().Field("hidden", "X").RegisterIn(r)
+			},
+		},
+		{
+			Name: "unrecognized mask func",
+			Register: func(r *masking.Registry) error {
+				return masking.ForType[S]().Field("Secret", "idk").RegisterIn(r)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// act
+			err := tc.Register(masking.NewRegistry())
+
+			// assert
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,444 @@
+package masking
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"unsafe"
+)
+
+// Fmt returns a fmt.Formatter that prints the masked representation of v.
+//
+// Printing with Fmt does not modify v. Struct fields are masked based on struct tagging as with
+// DeepMask, except that values behind nested pointers are printed as addresses, as the fmt package
+// does. The %v, %+v, %#v and %s verbs, along with their flags, follow the semantics of the fmt package:
+//
+//	fmt.Printf("%+v\n", masking.Fmt(req))
+//
+// Types implementing fmt.Formatter, fmt.Stringer, fmt.GoStringer or error are printed using those
+// methods on a masked copy of the value. Since maskers modify values in place, a value is printed as
+// [REDACTED] if masking it would modify memory shared with v, such as a SelfMasker backed by a map.
+func Fmt(v interface{}) fmt.Formatter {
+	return DefaultRegistry.Fmt(v)
+}
+
+// Fmt returns a fmt.Formatter that prints the masked representation of v using the maskers of r.
+func (r *Registry) Fmt(v interface{}) fmt.Formatter {
+	return formatter{r: r, v: v}
+}
+
+type formatter struct {
+	r *Registry
+	v interface{}
+}
+
+func (f formatter) Format(s fmt.State, verb rune) {
+	p := printer{
+		r:      f.r,
+		format: fmt.FormatString(s, verb),
+		verb:   verb,
+		plus:   s.Flag('+'),
+		sharp:  s.Flag('#') && verb == 'v',
+	}
+	val := reflect.ValueOf(f.v)
+	if val.IsValid() {
+		// an addressable copy allows fields reached through unexported fields to be read
+		val = copyOf(val).Elem()
+	}
+	p.printValue(val, 0)
+	s.Write(p.buf.Bytes())
+}
+
+type printer struct {
+	r      *Registry
+	buf    bytes.Buffer
+	format string
+	verb   rune
+	plus   bool
+	sharp  bool
+}
+
+func (p *printer) printValue(val reflect.Value, depth int) {
+	if !val.IsValid() {
+		p.buf.WriteString("<nil>")
+		return
+	}
+
+	if readableVal, ok := readable(val); ok && p.printMasked(readableVal, depth) {
+		return
+	}
+	p.printMaskedValue(val, depth)
+}
+
+// printMaskedValue prints val, to which any type masker or the type's own masking is already applied.
+func (p *printer) printMaskedValue(val reflect.Value, depth int) {
+	if val.CanInterface() && p.printWithMethods(val) {
+		return
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		p.printStruct(val, depth)
+
+	case reflect.Pointer:
+		switch {
+		case val.IsNil():
+			p.printPlain(val)
+		case depth == 0 && isComposite(val.Elem().Kind()):
+			// like the fmt package, only follow pointers at the top level
+			p.buf.WriteByte('&')
+			p.printValue(val.Elem(), depth+1)
+		case p.sharp:
+			fmt.Fprintf(&p.buf, "(%s)(0x%x)", val.Type(), val.Pointer())
+		case p.verb == 'v':
+			fmt.Fprintf(&p.buf, "0x%x", val.Pointer())
+		default:
+			fmt.Fprintf(&p.buf, "%%!%c(%s=0x%x)", p.verb, val.Type(), val.Pointer())
+		}
+
+	case reflect.Interface:
+		if val.IsNil() && p.sharp {
+			fmt.Fprintf(&p.buf, "%s(nil)", val.Type())
+			return
+		}
+		p.printValue(val.Elem(), depth+1)
+
+	case reflect.Slice, reflect.Array:
+		if p.mayHoldMaskedValues(val.Type().Elem()) {
+			p.printList(val, depth)
+		} else {
+			p.printPlain(val)
+		}
+
+	case reflect.Map:
+		p.printMap(val, depth)
+
+	default:
+		p.printPlain(val)
+	}
+}
+
+// printMasked prints a masked copy of val if a type masker or the type's own masking applies to it.
+func (p *printer) printMasked(val reflect.Value, depth int) bool {
+	ptr := copyOf(val)
+
+	if typeMaskFunc, found := p.r.typeMaskerFor(ptr); found {
+		p.printMaskedCopy(ptr, typeMaskFunc, depth)
+		return true
+	}
+
+	switch v := ptr.Interface().(type) {
+	case SelfMasker:
+		p.printMaskedCopy(ptr, func(_ context.Context, _ reflect.Value) error {
+			return v.MaskSensitive()
+		}, depth)
+		return true
+	case MaskedValuer:
+		// Masked returns a masked copy, leaving the value as is
+		if err := setMasked(ptr, v.Masked()); err != nil {
+			p.buf.WriteString(redacted)
+			return true
+		}
+		p.printMaskedValue(ptr.Elem(), depth)
+		return true
+	}
+
+	return false
+}
+
+// printWithMethods prints val using its formatting methods, if any, after masking a copy of it.
+func (p *printer) printWithMethods(val reflect.Value) bool {
+	if val.Kind() == reflect.Interface || (val.Kind() == reflect.Pointer && val.IsNil()) {
+		return false
+	}
+
+	if !hasFormatMethods(val.Interface(), p.sharp) {
+		return false
+	}
+
+	// masking a copy without following pointers leaves the original value untouched, unless maskers
+	// modify memory shared by the copy
+	var ptr, arg reflect.Value
+	if val.Kind() == reflect.Pointer {
+		ptr = copyOf(val.Elem())
+		arg = ptr
+	} else {
+		ptr = copyOf(val)
+		arg = ptr.Elem()
+	}
+	if p.masksSharedMemory(ptr.Type().Elem()) {
+		p.buf.WriteString(redacted)
+		return true
+	}
+	if err := p.r.mask(context.Background(), ptr, "", maskOptions{tagKeys: p.r.tagKeys}); err != nil {
+		p.buf.WriteString(redacted)
+		return true
+	}
+	fmt.Fprintf(&p.buf, p.format, arg.Interface())
+	return true
+}
+
+// printMaskedCopy prints the value pointed to by ptr once masked by maskFunc, or [REDACTED] if masking
+// the value would modify memory shared with the printed value.
+func (p *printer) printMaskedCopy(ptr reflect.Value, maskFunc maskFunc, depth int) {
+	if sharesMemory(ptr.Type().Elem()) {
+		p.buf.WriteString(redacted)
+		return
+	}
+	if err := maskFunc(context.Background(), ptr); err != nil {
+		p.buf.WriteString(redacted)
+		return
+	}
+	p.printMaskedValue(ptr.Elem(), depth)
+}
+
+func (p *printer) printStruct(val reflect.Value, depth int) {
+	t := val.Type()
+	if p.sharp {
+		p.buf.WriteString(t.String())
+	}
+	p.buf.WriteByte('{')
+
+	for i := 0; i < t.NumField(); i++ {
+		if i > 0 {
+			p.writeSeparator()
+		}
+		if p.plus || p.sharp {
+			p.buf.WriteString(t.Field(i).Name)
+			p.buf.WriteByte(':')
+		}
+
+		field := val.Field(i)
+		fieldMaskTag := ""
+		var err error
+		if t.Field(i).IsExported() && field.Kind() != reflect.Pointer {
+			fieldMaskTag, _, err = p.r.fieldMaskTag(t, i, p.r.tagKeys, "")
+		}
+
+		if err != nil {
+			p.buf.WriteString(redacted)
+			continue
+		}
+		if fieldMaskTag == "" {
+			p.printValue(field, depth+1)
+			continue
+		}
+
+		fieldMasker, err := p.r.getFieldMasker(fieldMaskTag)
+		if err != nil {
+			p.buf.WriteString(redacted)
+			continue
+		}
+		applies, err := fieldMasker.applies(val)
+		switch {
+		case err != nil:
+			p.buf.WriteString(redacted)
+		case !applies:
+			p.printValue(field, depth+1)
+		default:
+			readableField, ok := readable(field)
+			readableParent, _ := readable(val)
+			if !ok || !readableParent.IsValid() {
+				p.buf.WriteString(redacted)
+				continue
+			}
+			parent := copyOf(readableParent)
+			p.printMaskedCopy(copyOf(readableField), func(_ context.Context, ptr reflect.Value) error {
+				return fieldMasker.maskFunc(withParent(context.Background(), parent.Elem()), ptr)
+			}, depth+1)
+		}
+	}
+
+	p.buf.WriteByte('}')
+}
+
+func (p *printer) printList(val reflect.Value, depth int) {
+	if p.sharp {
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			fmt.Fprintf(&p.buf, "%s(nil)", val.Type())
+			return
+		}
+		p.buf.WriteString(val.Type().String())
+		p.buf.WriteByte('{')
+	} else {
+		p.buf.WriteByte('[')
+	}
+
+	for i := 0; i < val.Len(); i++ {
+		if i > 0 {
+			p.writeSeparator()
+		}
+		p.printValue(val.Index(i), depth+1)
+	}
+
+	if p.sharp {
+		p.buf.WriteByte('}')
+	} else {
+		p.buf.WriteByte(']')
+	}
+}
+
+func (p *printer) printMap(val reflect.Value, depth int) {
+	if p.sharp {
+		if val.IsNil() {
+			fmt.Fprintf(&p.buf, "%s(nil)", val.Type())
+			return
+		}
+		p.buf.WriteString(val.Type().String())
+		p.buf.WriteByte('{')
+	} else {
+		p.buf.WriteString("map[")
+	}
+
+	keys := val.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return lessKey(keys[i], keys[j])
+	})
+	for i, key := range keys {
+		if i > 0 {
+			p.writeSeparator()
+		}
+		p.printValue(key, depth+1)
+		p.buf.WriteByte(':')
+		p.printValue(val.MapIndex(key), depth+1)
+	}
+
+	if p.sharp {
+		p.buf.WriteByte('}')
+	} else {
+		p.buf.WriteByte(']')
+	}
+}
+
+// printPlain prints val, which holds no values subject to masking, using the fmt package.
+func (p *printer) printPlain(val reflect.Value) {
+	fmt.Fprintf(&p.buf, p.format, val)
+}
+
+func (p *printer) writeSeparator() {
+	if p.sharp {
+		p.buf.WriteString(", ")
+	} else {
+		p.buf.WriteByte(' ')
+	}
+}
+
+// copyOf returns a pointer to a shallow copy of val.
+func copyOf(val reflect.Value) reflect.Value {
+	ptr := reflect.New(val.Type())
+	ptr.Elem().Set(val)
+	return ptr
+}
+
+// readable returns val, or a read-only view of val if val was obtained through unexported fields, and
+// false if val cannot be read as such.
+func readable(val reflect.Value) (reflect.Value, bool) {
+	switch {
+	case val.CanInterface():
+		return val, true
+	case val.CanAddr():
+		return reflect.NewAt(val.Type(), unsafe.Pointer(val.UnsafeAddr())).Elem(), true
+	}
+	return reflect.Value{}, false
+}
+
+// sharesMemory returns true if a shallow copy of a value of type t shares memory with the value, such
+// as through a map, slice or pointer.
+func sharesMemory(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Array:
+		return sharesMemory(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if sharesMemory(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface, reflect.Chan, reflect.Func,
+		reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+// masksSharedMemory returns true if masking a shallow copy of a value of type t, as by Mask, may modify
+// memory shared with the value.
+func (p *printer) masksSharedMemory(t reflect.Type) bool {
+	if p.r.valueMasker(t) != "" && t.Kind() != reflect.Pointer {
+		return sharesMemory(t)
+	}
+
+	switch t.Kind() {
+	case reflect.Array:
+		return p.masksSharedMemory(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() || field.Type.Kind() == reflect.Pointer {
+				// unexported fields and values behind pointers are not masked
+				continue
+			}
+			maskTag, _, _ := p.r.fieldMaskTag(t, i, p.r.tagKeys, "")
+			if maskTag != "" && sharesMemory(field.Type) || maskTag == "" && p.masksSharedMemory(field.Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasFormatMethods returns true if the fmt package would print v using one of its methods.
+func hasFormatMethods(v interface{}, sharp bool) bool {
+	if _, ok := v.(fmt.Formatter); ok {
+		return true
+	}
+	if sharp {
+		_, ok := v.(fmt.GoStringer)
+		return ok
+	}
+	switch v.(type) {
+	case error, fmt.Stringer:
+		return true
+	}
+	return false
+}
+
+// mayHoldMaskedValues returns true if values of type t may need masking when printed.
+func (p *printer) mayHoldMaskedValues(t reflect.Type) bool {
+	if isComposite(t.Kind()) || t.Kind() == reflect.Interface {
+		return true
+	}
+	if _, found := p.r.typeMaskers[t]; found {
+		return true
+	}
+	ptrType := reflect.PointerTo(t)
+	return ptrType.Implements(selfMaskerType) || ptrType.Implements(maskedValuerType)
+}
+
+func isComposite(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// lessKey orders map keys for printing, similarly to the fmt package.
+func lessKey(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
@@ -0,0 +1,190 @@
+package masking_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Fmt_WithVerbs_PrintsMaskedRepresentation(t *testing.T) {
+	// arrange
+	type Card struct {
+		Number string `mask:"X,showback=4"`
+		Expiry string
+	}
+	type Request struct {
+		User     string
+		Password string `mask:"*"`
+		Cards    []Card
+		Tags     map[string]int
+		Meta     interface{}
+		Next     *Request
+		When     time.Time
+	}
+	when := time.Date(2022, 7, 4, 12, 0, 0, 0, time.UTC)
+	req := Request{
+		User:     "jsmith",
+		Password: "hunter2",
+		Cards:    []Card{{Number: "4111111111111111", Expiry: "01/30"}},
+		Tags:     map[string]int{"b": 2, "a": 1},
+		Meta:     Card{Number: "12345678", Expiry: "02/31"},
+		When:     when,
+	}
+	masked := Request{
+		User:     "jsmith",
+		Password: "*******",
+		Cards:    []Card{{Number: "XXXXXXXXXXXX1111", Expiry: "01/30"}},
+		Tags:     map[string]int{"b": 2, "a": 1},
+		Meta:     Card{Number: "XXXX5678", Expiry: "02/31"},
+		When:     when,
+	}
+	formats := []string{"%v", "%+v", "%#v", "%s"}
+
+	for _, format := range formats {
+		t.Run(format, func(t *testing.T) {
+			// act
+			str := fmt.Sprintf(format, masking.Fmt(&req))
+
+			// assert
+			assert.Equal(t, fmt.Sprintf(format, &masked), str)
+		})
+	}
+}
+
+func Test_Fmt_DoesNotModifyValue(t *testing.T) {
+	// arrange
+	type Card struct {
+		Number string `mask:"X,showback=4"`
+		Expiry string
+	}
+	type Request struct {
+		User     string
+		Password string `mask:"*"`
+		Cards    []Card
+		Tags     map[string]int
+		Meta     interface{}
+		Next     *Request
+		When     time.Time
+	}
+	req := Request{
+		User:     "jsmith",
+		Password: "hunter2",
+		Cards:    []Card{{Number: "4111111111111111", Expiry: "01/30"}},
+	}
+
+	// act
+	str := fmt.Sprintf("%+v", masking.Fmt(req))
+
+	// assert
+	assert.Contains(t, str, "Password:*******")
+	assert.Equal(t, "hunter2", req.Password)
+	assert.Equal(t, "4111111111111111", req.Cards[0].Number)
+}
+
+func Test_Fmt_WithSelfMaskerAndSecret_PrintsMaskedValues(t *testing.T) {
+	// arrange
+	type Payment struct {
+		Card  CardNumber
+		Email Email
+		Token masking.Secret[string]
+	}
+	p := Payment{
+		Card:  CardNumber{Number: "4111111111111111"},
+		Email: "jane@example.com",
+		Token: masking.NewSecret("abcdef", "*"),
+	}
+
+	// act
+	str := fmt.Sprintf("%v", masking.Fmt(p))
+
+	// assert
+	assert.Equal(t, "{{################} ***@example.com ******}", str)
+	assert.Equal(t, "4111111111111111", p.Card.Number)
+}
+
+func Test_Fmt_WithStringer_CallsStringOnMaskedCopy(t *testing.T) {
+	// arrange
+	type Outer struct {
+		Login StringerLogin
+	}
+	o := Outer{
+		Login: StringerLogin{User: "jsmith", Password: "hunter2"},
+	}
+
+	// act
+	str := fmt.Sprintf("%v", masking.Fmt(o))
+
+	// assert
+	assert.Equal(t, "{jsmith:*******}", str)
+}
+
+type StringerLogin struct {
+	User     string
+	Password string `mask:"*"`
+}
+
+func (l StringerLogin) String() string {
+	return l.User + ":" + l.Password
+}
+
+func Test_Fmt_WithConditions_MasksOnlyIfConditionsHold(t *testing.T) {
+	// arrange
+	contacts := []Contact{
+		{Country: "US", Phone: "555-123-4567", Email: "jane@example.com"},
+		{Country: "CA", Public: true, Phone: "555-765-4321", Email: "info@example.com"},
+	}
+
+	// act
+	out := fmt.Sprintf("%v", masking.Fmt(contacts))
+
+	// assert
+	assert.Equal(t, "[{US false XXXXXXXX4567 ****************} {CA true 555-765-4321 info@example.com}]", out)
+}
+
+type fmtHeaders map[string]string
+
+func (h fmtHeaders) MaskSensitive(args ...string) error {
+	for key := range h {
+		h[key] = "***"
+	}
+	return nil
+}
+
+func Test_Fmt_WithMapBackedSelfMasker_PrintsRedactedAndDoesNotModifyValue(t *testing.T) {
+	// arrange
+	type Request struct {
+		Path    string
+		Headers fmtHeaders
+	}
+	req := Request{Path: "/login", Headers: fmtHeaders{"Authorization": "Bearer abc"}}
+
+	// act
+	str := fmt.Sprintf("%v", masking.Fmt(req))
+
+	// assert
+	assert.Equal(t, "{/login [REDACTED]}", str)
+	assert.Equal(t, "Bearer abc", req.Headers["Authorization"])
+}
+
+func Test_Fmt_WithTaggedFieldWithinUnexportedField_PrintsMaskedValue(t *testing.T) {
+	// arrange
+	type Credentials struct {
+		User     string
+		Password string `mask:"*"`
+	}
+	type Session struct {
+		Id    string
+		creds Credentials
+	}
+	session := Session{Id: "s-1", creds: Credentials{User: "jsmith", Password: "hunter2"}}
+
+	// act
+	str := fmt.Sprintf("%+v", masking.Fmt(session))
+
+	// assert
+	assert.Equal(t, "{Id:s-1 creds:{User:jsmith Password:*******}}", str)
+	assert.Equal(t, "hunter2", session.creds.Password)
+}
@@ -0,0 +1,220 @@
+// Package grpcmask provides gRPC interceptors that log masked copies of protobuf messages.
+package grpcmask
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/dgravesa/go-mask/masking"
+)
+
+// Entry is a masked copy of the messages of a gRPC call for logging.
+//
+// Unary calls are logged with both messages once the call completes. Each message sent or received on
+// a stream is logged separately, with only Request or Response set.
+type Entry struct {
+	FullMethod string
+	// Request is a masked copy of the request message, if any.
+	Request proto.Message
+	// Response is a masked copy of the response message, if any.
+	Response proto.Message
+	Err      error
+	Duration time.Duration
+}
+
+// Logger receives masked copies of gRPC messages.
+type Logger interface {
+	LogEntry(ctx context.Context, e *Entry)
+}
+
+// LoggerFunc is an adapter to allow the use of ordinary functions as a Logger.
+type LoggerFunc func(ctx context.Context, e *Entry)
+
+// LogEntry calls f(ctx, e).
+func (f LoggerFunc) LogEntry(ctx context.Context, e *Entry) {
+	f(ctx, e)
+}
+
+// Config configures the masking and logging of gRPC messages.
+type Config struct {
+	// Logger receives the masked messages.
+	Logger Logger
+	// Rules maps fully qualified proto field names, such as "acme.v1.User.email", to masker tags.
+	// String fields, and repeated and map fields of strings, are masked by the masker. Fields of other
+	// kinds are cleared.
+	Rules map[string]string
+	// MaskOption is a custom field option of string type holding a masker tag, such as the extension
+	// declared by `extend google.protobuf.FieldOptions { string mask = 50000; }`. Rules take precedence
+	// over the option.
+	MaskOption protoreflect.ExtensionType
+	// Registry provides the maskers. If nil, masking.DefaultRegistry is used.
+	Registry *masking.Registry
+}
+
+// Interceptors log masked copies of the messages of gRPC calls.
+type Interceptors struct {
+	logger   Logger
+	registry *masking.Registry
+	rules    masking.ProtoRules
+}
+
+// New returns Interceptors configured by cfg.
+func New(cfg Config) (*Interceptors, error) {
+	if cfg.Logger == nil {
+		return nil, fmt.Errorf("grpcmask: logger is required")
+	}
+
+	i := &Interceptors{
+		logger:   cfg.Logger,
+		registry: cfg.Registry,
+		rules: masking.ProtoRules{
+			Fields:          cfg.Rules,
+			Option:          cfg.MaskOption,
+			ClearUnmaskable: true,
+		},
+	}
+	if i.registry == nil {
+		i.registry = masking.DefaultRegistry
+	}
+	if err := i.rules.Validate(); err != nil {
+		return nil, fmt.Errorf("grpcmask: %w", err)
+	}
+
+	return i, nil
+}
+
+// UnaryServerInterceptor returns a server interceptor that logs masked unary calls.
+func (i *Interceptors) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		i.logUnary(ctx, info.FullMethod, req, resp, err, start)
+		return resp, err
+	}
+}
+
+// UnaryClientInterceptor returns a client interceptor that logs masked unary calls.
+func (i *Interceptors) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err != nil {
+			reply = nil
+		}
+		i.logUnary(ctx, method, req, reply, err, start)
+		return err
+	}
+}
+
+// StreamServerInterceptor returns a server interceptor that logs masked stream messages.
+func (i *Interceptors) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{
+			ServerStream: ss,
+			interceptors: i,
+			fullMethod:   info.FullMethod,
+		})
+	}
+}
+
+// StreamClientInterceptor returns a client interceptor that logs masked stream messages.
+func (i *Interceptors) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			i.logger.LogEntry(ctx, &Entry{FullMethod: method, Err: err})
+			return nil, err
+		}
+		return &clientStream{
+			ClientStream: cs,
+			interceptors: i,
+			fullMethod:   method,
+		}, nil
+	}
+}
+
+func (i *Interceptors) logUnary(ctx context.Context, method string, req, resp interface{}, err error,
+	start time.Time) {
+	i.logger.LogEntry(ctx, &Entry{
+		FullMethod: method,
+		Request:    i.maskedCopy(req),
+		Response:   i.maskedCopy(resp),
+		Err:        err,
+		Duration:   time.Since(start),
+	})
+}
+
+func (i *Interceptors) logStreamMessage(ctx context.Context, method string, req, resp interface{}) {
+	i.logger.LogEntry(ctx, &Entry{
+		FullMethod: method,
+		Request:    i.maskedCopy(req),
+		Response:   i.maskedCopy(resp),
+	})
+}
+
+// maskedCopy returns a masked copy of msg, leaving msg untouched.
+func (i *Interceptors) maskedCopy(msg interface{}) proto.Message {
+	protoMsg, ok := msg.(proto.Message)
+	if !ok || protoMsg == nil {
+		return nil
+	}
+
+	masked := proto.Clone(protoMsg)
+	if err := i.registry.MaskProto(masked, i.rules); err != nil {
+		return nil
+	}
+	return masked
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	interceptors *Interceptors
+	fullMethod   string
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.interceptors.logStreamMessage(s.Context(), s.fullMethod, m, nil)
+	return nil
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+	s.interceptors.logStreamMessage(s.Context(), s.fullMethod, nil, m)
+	return nil
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	interceptors *Interceptors
+	fullMethod   string
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	if err := s.ClientStream.SendMsg(m); err != nil {
+		return err
+	}
+	s.interceptors.logStreamMessage(s.Context(), s.fullMethod, m, nil)
+	return nil
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.interceptors.logStreamMessage(s.Context(), s.fullMethod, nil, m)
+	return nil
+}
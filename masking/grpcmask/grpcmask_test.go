@@ -0,0 +1,239 @@
+package grpcmask_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/dgravesa/go-mask/masking/grpcmask"
+)
+
+var usersServiceDesc = grpc.ServiceDesc{
+	ServiceName: "test.v1.Users",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error,
+				interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := dynamicpb.NewMessage(userDesc)
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				handler := func(_ context.Context, req interface{}) (interface{}, error) {
+					return proto.Clone(req.(proto.Message)), nil
+				}
+				if interceptor == nil {
+					return handler(ctx, req)
+				}
+				return interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/test.v1.Users/Get"}, handler)
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			ServerStreams: true,
+			ClientStreams: true,
+			Handler: func(_ interface{}, stream grpc.ServerStream) error {
+				for {
+					req := dynamicpb.NewMessage(userDesc)
+					if err := stream.RecvMsg(req); err == io.EOF {
+						return nil
+					} else if err != nil {
+						return err
+					}
+					if err := stream.SendMsg(req); err != nil {
+						return err
+					}
+				}
+			},
+		},
+	},
+}
+
+type entryRecorder struct {
+	mu      sync.Mutex
+	entries []*grpcmask.Entry
+}
+
+func (r *entryRecorder) LogEntry(_ context.Context, e *grpcmask.Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, e)
+}
+
+func (r *entryRecorder) Entries() []*grpcmask.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.entries
+}
+
+// startServer starts a test server on an in-memory connection and returns a client connection to it.
+func startServer(t *testing.T, serverOpts []grpc.ServerOption, dialOpts ...grpc.DialOption) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(serverOpts...)
+	server.RegisterService(&usersServiceDesc, struct{}{})
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	dialOpts = append(dialOpts,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient("passthrough:///bufnet", dialOpts...)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func newInterceptors(t *testing.T, recorder *entryRecorder) *grpcmask.Interceptors {
+	interceptors, err := grpcmask.New(grpcmask.Config{
+		Logger: recorder,
+		Rules: map[string]string{
+			"test.v1.User.ssn":       "X,showback=4",
+			"test.v1.User.tokens":    "*",
+			"test.v1.User.labels":    "x",
+			"test.v1.User.phone":     "simple,#",
+			"test.v1.User.pin":       "*",
+			"test.v1.Address.street": "-",
+		},
+		MaskOption: maskOption,
+	})
+	assert.NoError(t, err)
+	return interceptors
+}
+
+func Test_UnaryServerInterceptor_LogsMaskedCopies(t *testing.T) {
+	// arrange
+	var recorder entryRecorder
+	interceptors := newInterceptors(t, &recorder)
+	conn := startServer(t, []grpc.ServerOption{grpc.UnaryInterceptor(interceptors.UnaryServerInterceptor())})
+	req := newUser(map[string]string{
+		"name":  "Jane",
+		"email": "jane@example.com",
+		"ssn":   "123-45-6789",
+		"phone": "555-1234",
+	})
+	fields := userDesc.Fields()
+	tokens := req.Mutable(fields.ByName("tokens")).List()
+	tokens.Append(protoreflect.ValueOfString("abc"))
+	tokens.Append(protoreflect.ValueOfString("defg"))
+	req.Mutable(fields.ByName("labels")).Map().Set(
+		protoreflect.ValueOfString("team").MapKey(), protoreflect.ValueOfString("blue"))
+	address := dynamicpb.NewMessage(addressDesc)
+	address.Set(addressDesc.Fields().ByName("street"), protoreflect.ValueOfString("1 Main St"))
+	req.Set(fields.ByName("address"), protoreflect.ValueOfMessage(address))
+	req.Set(fields.ByName("pin"), protoreflect.ValueOfInt32(1234))
+	resp := dynamicpb.NewMessage(userDesc)
+
+	// act
+	err := conn.Invoke(context.Background(), "/test.v1.Users/Get", req, resp)
+
+	// assert
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(req, resp))
+	assert.Equal(t, "123-45-6789", getString(req, "ssn"))
+	entries := recorder.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "/test.v1.Users/Get", entries[0].FullMethod)
+	for _, logged := range []proto.Message{entries[0].Request, entries[0].Response} {
+		msg := logged.ProtoReflect()
+		assert.Equal(t, "Jane", getString(logged, "name"))
+		assert.Equal(t, "XXXXXXXXXXXXXXXX", getString(logged, "email"))
+		assert.Equal(t, "XXXXXXX6789", getString(logged, "ssn"))
+		assert.Equal(t, "########", getString(logged, "phone"))
+		assert.Equal(t, "***", msg.Get(fields.ByName("tokens")).List().Get(0).String())
+		assert.Equal(t, "****", msg.Get(fields.ByName("tokens")).List().Get(1).String())
+		assert.Equal(t, "xxxx", msg.Get(fields.ByName("labels")).Map().Get(
+			protoreflect.ValueOfString("team").MapKey()).String())
+		assert.Equal(t, "---------", getString(msg.Get(fields.ByName("address")).Message().Interface(), "street"))
+		assert.False(t, msg.Has(fields.ByName("pin")))
+	}
+}
+
+func Test_UnaryClientInterceptor_LogsMaskedCopies(t *testing.T) {
+	// arrange
+	var recorder entryRecorder
+	interceptors := newInterceptors(t, &recorder)
+	conn := startServer(t, nil, grpc.WithUnaryInterceptor(interceptors.UnaryClientInterceptor()))
+	req := newUser(map[string]string{"ssn": "123-45-6789"})
+	resp := dynamicpb.NewMessage(userDesc)
+
+	// act
+	err := conn.Invoke(context.Background(), "/test.v1.Users/Get", req, resp)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "123-45-6789", getString(resp, "ssn"))
+	entries := recorder.Entries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "XXXXXXX6789", getString(entries[0].Request, "ssn"))
+	assert.Equal(t, "XXXXXXX6789", getString(entries[0].Response, "ssn"))
+}
+
+func Test_StreamInterceptors_LogMaskedCopiesOfEachMessage(t *testing.T) {
+	// arrange
+	var serverRecorder, clientRecorder entryRecorder
+	serverInterceptors := newInterceptors(t, &serverRecorder)
+	clientInterceptors := newInterceptors(t, &clientRecorder)
+	conn := startServer(t,
+		[]grpc.ServerOption{grpc.StreamInterceptor(serverInterceptors.StreamServerInterceptor())},
+		grpc.WithStreamInterceptor(clientInterceptors.StreamClientInterceptor()))
+	stream, err := conn.NewStream(context.Background(), &usersServiceDesc.Streams[0], "/test.v1.Users/Stream")
+	assert.NoError(t, err)
+
+	// act
+	err = stream.SendMsg(newUser(map[string]string{"email": "jane@example.com"}))
+	assert.NoError(t, err)
+	resp := dynamicpb.NewMessage(userDesc)
+	err = stream.RecvMsg(resp)
+	assert.NoError(t, err)
+	err = stream.CloseSend()
+	assert.NoError(t, err)
+	err = stream.RecvMsg(dynamicpb.NewMessage(userDesc))
+
+	// assert
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, "jane@example.com", getString(resp, "email"))
+	for _, recorder := range []*entryRecorder{&serverRecorder, &clientRecorder} {
+		entries := recorder.Entries()
+		assert.Len(t, entries, 2)
+		assert.Equal(t, "XXXXXXXXXXXXXXXX", getString(entries[0].Request, "email"))
+		assert.Nil(t, entries[0].Response)
+		assert.Nil(t, entries[1].Request)
+		assert.Equal(t, "XXXXXXXXXXXXXXXX", getString(entries[1].Response, "email"))
+	}
+}
+
+func Test_New_WithInvalidConfig_ReturnsError(t *testing.T) {
+	// arrange
+	testCases := map[string]grpcmask.Config{
+		"no logger": {},
+		"invalid rule": {
+			Logger: grpcmask.LoggerFunc(func(context.Context, *grpcmask.Entry) {}),
+			Rules:  map[string]string{"test.v1.User..ssn": "X"},
+		},
+	}
+
+	for name, cfg := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// act
+			_, err := grpcmask.New(cfg)
+
+			// assert
+			assert.Error(t, err)
+		})
+	}
+}
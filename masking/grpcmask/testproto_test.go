@@ -0,0 +1,175 @@
+package grpcmask_test
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// The test messages are built at runtime, equivalent to the following protos:
+//
+//	// mask.proto
+//	package test.mask;
+//	extend google.protobuf.FieldOptions { string mask = 50000; }
+//
+//	// user.proto
+//	package test.v1;
+//	message Address { string street = 1; }
+//	message User {
+//	  string name = 1;
+//	  string email = 2 [(test.mask.mask) = "X"];
+//	  string ssn = 3;
+//	  repeated string tokens = 4;
+//	  map<string, string> labels = 5;
+//	  Address address = 6;
+//	  oneof contact { string phone = 7; string fax = 8; }
+//	  int32 pin = 9;
+//	}
+//	service Users {
+//	  rpc Get(User) returns (User);
+//	  rpc Stream(stream User) returns (stream User);
+//	}
+var (
+	maskOption  protoreflect.ExtensionType
+	userDesc    protoreflect.MessageDescriptor
+	addressDesc protoreflect.MessageDescriptor
+)
+
+func init() {
+	maskFile, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("test/mask.proto"),
+		Package:    proto.String("test.mask"),
+		Dependency: []string{"google/protobuf/descriptor.proto"},
+		Extension: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     proto.String("mask"),
+				Number:   proto.Int32(50000),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				Extendee: proto.String(".google.protobuf.FieldOptions"),
+				JsonName: proto.String("mask"),
+			},
+		},
+		Syntax: proto.String("proto3"),
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		panic(err)
+	}
+	maskOption = dynamicpb.NewExtensionType(maskFile.Extensions().Get(0))
+
+	emailOptions := &descriptorpb.FieldOptions{}
+	proto.SetExtension(emailOptions, maskOption, "X")
+
+	files := new(protoregistry.Files)
+	files.RegisterFile(maskFile)
+	userFile, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("test/user.proto"),
+		Package:    proto.String("test.v1"),
+		Dependency: []string{"test/mask.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Address"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					stringField("street", 1),
+				},
+			},
+			{
+				Name: proto.String("User"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					stringField("name", 1),
+					withOptions(stringField("email", 2), emailOptions),
+					stringField("ssn", 3),
+					repeated(stringField("tokens", 4)),
+					{
+						Name:     proto.String("labels"),
+						Number:   proto.Int32(5),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+						TypeName: proto.String(".test.v1.User.LabelsEntry"),
+						JsonName: proto.String("labels"),
+					},
+					{
+						Name:     proto.String("address"),
+						Number:   proto.Int32(6),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+						TypeName: proto.String(".test.v1.Address"),
+						JsonName: proto.String("address"),
+					},
+					inOneof(stringField("phone", 7)),
+					inOneof(stringField("fax", 8)),
+					{
+						Name:     proto.String("pin"),
+						Number:   proto.Int32(9),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+						JsonName: proto.String("pin"),
+					},
+				},
+				NestedType: []*descriptorpb.DescriptorProto{
+					{
+						Name: proto.String("LabelsEntry"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							stringField("key", 1),
+							stringField("value", 2),
+						},
+						Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+					},
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{
+					{Name: proto.String("contact")},
+				},
+			},
+		},
+		Syntax: proto.String("proto3"),
+	}, files)
+	if err != nil {
+		panic(err)
+	}
+	addressDesc = userFile.Messages().ByName("Address")
+	userDesc = userFile.Messages().ByName("User")
+}
+
+func stringField(name string, number int32) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		JsonName: proto.String(name),
+	}
+}
+
+func withOptions(fd *descriptorpb.FieldDescriptorProto,
+	opts *descriptorpb.FieldOptions) *descriptorpb.FieldDescriptorProto {
+	fd.Options = opts
+	return fd
+}
+
+func repeated(fd *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+	fd.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return fd
+}
+
+func inOneof(fd *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+	fd.OneofIndex = proto.Int32(0)
+	return fd
+}
+
+// newUser returns a test.v1.User message with the given string fields set.
+func newUser(fields map[string]string) *dynamicpb.Message {
+	msg := dynamicpb.NewMessage(userDesc)
+	for name, val := range fields {
+		msg.Set(userDesc.Fields().ByName(protoreflect.Name(name)), protoreflect.ValueOfString(val))
+	}
+	return msg
+}
+
+// getString returns the named string field of msg.
+func getString(msg proto.Message, name string) string {
+	m := msg.ProtoReflect()
+	return m.Get(m.Descriptor().Fields().ByName(protoreflect.Name(name))).String()
+}
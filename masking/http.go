@@ -0,0 +1,228 @@
+package masking
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultHeaderPolicy masks the values of well-known sensitive HTTP headers.
+var DefaultHeaderPolicy = KeyPolicy{
+	Rules: []KeyRule{
+		{Key: "Authorization", Mask: "*"},
+		{Key: "Proxy-Authorization", Mask: "*"},
+		{Key: "Cookie", Mask: "*"},
+		{Key: "Set-Cookie", Mask: "*"},
+		{Key: "X-Api-Key", Mask: "*"},
+		{Key: "X-Auth-Token", Mask: "*"},
+		{Key: "X-Csrf-Token", Mask: "*"},
+	},
+	IgnoreCase: true,
+}
+
+// SegmentRule associates a pattern for URL path segments with a masker tag.
+type SegmentRule struct {
+	// Pattern is a regular expression that must match an entire path segment, such as "[0-9]{6,}".
+	Pattern string
+	// Mask is the masker tag to apply to matching segments.
+	Mask string
+}
+
+// URLPolicy selects the masking to apply to URLs.
+type URLPolicy struct {
+	// Password is the masker tag to apply to the password of the URL's user information. No masking is
+	// applied to passwords if Password is empty.
+	Password string
+	// Query selects the masking to apply to query parameter values by parameter name.
+	Query KeyPolicy
+	// PathSegments selects the masking to apply to path segments. The first matching rule is applied.
+	PathSegments []SegmentRule
+}
+
+// DefaultURLPolicy masks passwords, as with url.URL.Redacted, and commonly sensitive query parameters.
+var DefaultURLPolicy = URLPolicy{
+	Password: "x",
+	Query: KeyPolicy{
+		Rules: []KeyRule{
+			{Key: "*token*", Mask: "*"},
+			{Key: "api_key", Mask: "*"},
+			{Key: "apikey", Mask: "*"},
+			{Key: "key", Mask: "*"},
+			{Key: "password", Mask: "*"},
+			{Key: "secret", Mask: "*"},
+			{Key: "sig", Mask: "*"},
+			{Key: "signature", Mask: "*"},
+		},
+		IgnoreCase: true,
+	},
+}
+
+// MaskHeader applies masking to values of h based on header names.
+func MaskHeader(h http.Header, policy KeyPolicy) error {
+	return DefaultRegistry.MaskHeader(h, policy)
+}
+
+// MaskHeader applies masking to values of h based on header names using the maskers of r.
+func (r *Registry) MaskHeader(h http.Header, policy KeyPolicy) error {
+	for name, vals := range h {
+		maskTag, found := policy.Match(name)
+		if !found {
+			continue
+		}
+		for i := range vals {
+			if err := r.MaskValue(&vals[i], maskTag); err != nil {
+				return fmt.Errorf("header %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// MaskURL applies masking to the password, query parameters and path segments of u.
+//
+// Masked query parameter and path segment values keep common mask characters, such as '*', unescaped.
+func MaskURL(u *url.URL, policy URLPolicy) error {
+	return DefaultRegistry.MaskURL(u, policy)
+}
+
+// MaskURL applies masking to the password, query parameters and path segments of u using the maskers
+// of r.
+func (r *Registry) MaskURL(u *url.URL, policy URLPolicy) error {
+	if err := r.maskURLPassword(u, policy.Password); err != nil {
+		return err
+	}
+	if err := r.maskURLQuery(u, policy.Query); err != nil {
+		return err
+	}
+	return r.maskURLPath(u, policy.PathSegments)
+}
+
+// ValidateURLPolicy checks that the path segment patterns of policy compile and that its masker tags
+// refer to maskers of DefaultRegistry.
+func ValidateURLPolicy(policy URLPolicy) error {
+	return DefaultRegistry.ValidateURLPolicy(policy)
+}
+
+// ValidateURLPolicy checks that the path segment patterns of policy compile and that its masker tags
+// refer to maskers of r.
+func (r *Registry) ValidateURLPolicy(policy URLPolicy) error {
+	if policy.Password != "" {
+		if _, err := r.getMaskFunc(policy.Password); err != nil {
+			return fmt.Errorf("url password: %w", err)
+		}
+	}
+	for _, rule := range policy.Query.Rules {
+		if _, err := r.getMaskFunc(rule.Mask); err != nil {
+			return fmt.Errorf("url query %s: %w", rule.Key, err)
+		}
+	}
+	for _, rule := range policy.PathSegments {
+		if _, err := compileSegmentPattern(rule.Pattern); err != nil {
+			return err
+		}
+		if _, err := r.getMaskFunc(rule.Mask); err != nil {
+			return fmt.Errorf("url path segment %s: %w", rule.Pattern, err)
+		}
+	}
+	return nil
+}
+
+func (r *Registry) maskURLPassword(u *url.URL, maskTag string) error {
+	if u.User == nil || maskTag == "" {
+		return nil
+	}
+	password, hasPassword := u.User.Password()
+	if !hasPassword {
+		return nil
+	}
+
+	if err := r.MaskValue(&password, maskTag); err != nil {
+		return fmt.Errorf("url password: %w", err)
+	}
+	u.User = url.UserPassword(u.User.Username(), password)
+	return nil
+}
+
+func (r *Registry) maskURLQuery(u *url.URL, policy KeyPolicy) error {
+	if u.RawQuery == "" || len(policy.Rules) == 0 {
+		return nil
+	}
+
+	// rewrite the raw query in place to preserve parameter order and encoding
+	params := strings.Split(u.RawQuery, "&")
+	for i, param := range params {
+		rawKey, rawVal, _ := strings.Cut(param, "=")
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			continue
+		}
+		maskTag, found := policy.Match(key)
+		if !found {
+			continue
+		}
+
+		val, err := url.QueryUnescape(rawVal)
+		if err != nil {
+			val = rawVal
+		}
+		if err := r.MaskValue(&val, maskTag); err != nil {
+			return fmt.Errorf("url query %s: %w", key, err)
+		}
+		params[i] = rawKey + "=" + keepMaskChars(url.QueryEscape(val))
+	}
+
+	u.RawQuery = strings.Join(params, "&")
+	return nil
+}
+
+func (r *Registry) maskURLPath(u *url.URL, rules []SegmentRule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	segments := strings.Split(u.Path, "/")
+	rawSegments := make([]string, len(segments))
+	for i, segment := range segments {
+		for _, rule := range rules {
+			pattern, err := compileSegmentPattern(rule.Pattern)
+			if err != nil {
+				return err
+			}
+			if pattern.MatchString(segment) {
+				if err := r.MaskValue(&segment, rule.Mask); err != nil {
+					return fmt.Errorf("url path segment: %w", err)
+				}
+				segments[i] = segment
+				break
+			}
+		}
+		rawSegments[i] = keepMaskChars(url.PathEscape(segments[i]))
+	}
+
+	u.Path = strings.Join(segments, "/")
+	u.RawPath = strings.Join(rawSegments, "/")
+	return nil
+}
+
+// keepMaskChars unescapes '*', which is permitted unescaped within URL paths and queries.
+func keepMaskChars(escaped string) string {
+	return strings.ReplaceAll(escaped, "%2A", "*")
+}
+
+var segmentPatterns sync.Map
+
+func compileSegmentPattern(pattern string) (*regexp.Regexp, error) {
+	if re, found := segmentPatterns.Load(pattern); found {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("url path segment pattern: %w", err)
+	}
+	segmentPatterns.Store(pattern, re)
+	return re, nil
+}
@@ -0,0 +1,89 @@
+package httpmask
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/dgravesa/go-mask/masking"
+)
+
+// redacted is logged in place of values that could not be masked.
+const redacted = "[REDACTED]"
+
+func errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("httpmask: "+format, args...)
+}
+
+// PathRule associates a path within a JSON body with a masker tag.
+//
+// See masking.PathRule for the path syntax.
+type PathRule = masking.PathRule
+
+// bodyMasker masks JSON and form request and response bodies.
+type bodyMasker struct {
+	registry *masking.Registry
+	policy   masking.JSONPolicy
+}
+
+func newBodyMasker(registry *masking.Registry, keys masking.KeyPolicy, paths []PathRule) (*bodyMasker, error) {
+	policy := masking.JSONPolicy{
+		Keys:  keys,
+		Paths: paths,
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, errorf("%w", err)
+	}
+	return &bodyMasker{
+		registry: registry,
+		policy:   policy,
+	}, nil
+}
+
+// mask returns a masked copy of body, or nil if body is empty or cannot be masked.
+func (bm *bodyMasker) mask(contentType string, body []byte) []byte {
+	if len(body) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		masked, err := bm.registry.MaskJSON(body, bm.policy)
+		if err != nil {
+			return nil
+		}
+		return masked
+	case mediaType == "application/x-www-form-urlencoded":
+		masked, err := bm.maskForm(body)
+		if err != nil {
+			return nil
+		}
+		return masked
+	}
+
+	// other bodies cannot be masked, so they are not logged
+	return nil
+}
+
+func (bm *bodyMasker) maskForm(body []byte) ([]byte, error) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for key, vals := range form {
+		maskTag, found := bm.policy.Keys.Match(key)
+		if !found {
+			continue
+		}
+		for i := range vals {
+			if err := bm.registry.MaskValue(&vals[i], maskTag); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return []byte(form.Encode()), nil
+}
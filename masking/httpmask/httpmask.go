@@ -0,0 +1,259 @@
+// Package httpmask provides net/http middleware that logs masked copies of HTTP requests and responses.
+package httpmask
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/dgravesa/go-mask/masking"
+)
+
+// DefaultMaxBodySize is the number of body bytes captured when Config.MaxBodySize is not set.
+const DefaultMaxBodySize = 64 << 10
+
+// Exchange is a masked copy of an HTTP request and its response.
+type Exchange struct {
+	Method string
+	// URL is the request URL with sensitive values masked, or "[REDACTED]" if it could not be masked.
+	URL            string
+	RequestHeader  http.Header
+	RequestBody    []byte
+	StatusCode     int
+	ResponseHeader http.Header
+	ResponseBody   []byte
+	Duration       time.Duration
+}
+
+// Logger receives masked copies of HTTP exchanges.
+type Logger interface {
+	LogExchange(ctx context.Context, e *Exchange)
+}
+
+// LoggerFunc is an adapter to allow the use of ordinary functions as a Logger.
+type LoggerFunc func(ctx context.Context, e *Exchange)
+
+// LogExchange calls f(ctx, e).
+func (f LoggerFunc) LogExchange(ctx context.Context, e *Exchange) {
+	f(ctx, e)
+}
+
+// SlogLogger returns a Logger that logs exchanges to l at info level.
+func SlogLogger(l *slog.Logger) Logger {
+	return LoggerFunc(func(ctx context.Context, e *Exchange) {
+		l.InfoContext(ctx, "http exchange",
+			slog.String("method", e.Method),
+			slog.String("url", e.URL),
+			slog.Any("request_header", e.RequestHeader),
+			slog.String("request_body", string(e.RequestBody)),
+			slog.Int("status", e.StatusCode),
+			slog.Any("response_header", e.ResponseHeader),
+			slog.String("response_body", string(e.ResponseBody)),
+			slog.Duration("duration", e.Duration))
+	})
+}
+
+// Config configures the masking and logging of HTTP exchanges.
+type Config struct {
+	// Logger receives the masked exchanges.
+	Logger Logger
+	// MaxBodySize limits the number of body bytes captured for logging. Bodies exceeding the limit are
+	// not logged, since they cannot be reliably masked.
+	MaxBodySize int
+	// BodyKeys selects maskers for JSON and form body values by key. If nil, masking.DefaultKeyPolicy
+	// is used.
+	BodyKeys *masking.KeyPolicy
+	// BodyPaths selects maskers for JSON body values by path.
+	BodyPaths []PathRule
+	// Headers selects maskers for header values by header name. If nil, masking.DefaultHeaderPolicy
+	// is used.
+	Headers *masking.KeyPolicy
+	// URL selects maskers for the request URL. If nil, masking.DefaultURLPolicy is used.
+	URL *masking.URLPolicy
+	// Registry provides the maskers. If nil, masking.DefaultRegistry is used.
+	Registry *masking.Registry
+}
+
+// Middleware logs masked copies of the HTTP exchanges of a handler.
+type Middleware struct {
+	logger      Logger
+	maxBodySize int
+	bodyMasker  *bodyMasker
+	headers     masking.KeyPolicy
+	url         masking.URLPolicy
+	registry    *masking.Registry
+}
+
+// New returns a Middleware configured by cfg.
+func New(cfg Config) (*Middleware, error) {
+	m := &Middleware{
+		logger:      cfg.Logger,
+		maxBodySize: cfg.MaxBodySize,
+		headers:     masking.DefaultHeaderPolicy,
+		url:         masking.DefaultURLPolicy,
+		registry:    cfg.Registry,
+	}
+	if m.logger == nil {
+		return nil, errorf("logger is required")
+	}
+	if m.maxBodySize <= 0 {
+		m.maxBodySize = DefaultMaxBodySize
+	}
+	if cfg.Headers != nil {
+		m.headers = *cfg.Headers
+	}
+	if cfg.URL != nil {
+		m.url = *cfg.URL
+	}
+	if m.registry == nil {
+		m.registry = masking.DefaultRegistry
+	}
+	if err := m.registry.ValidateURLPolicy(m.url); err != nil {
+		return nil, errorf("%w", err)
+	}
+
+	bodyKeys := masking.DefaultKeyPolicy
+	if cfg.BodyKeys != nil {
+		bodyKeys = *cfg.BodyKeys
+	}
+	var err error
+	m.bodyMasker, err = newBodyMasker(m.registry, bodyKeys, cfg.BodyPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Handler returns a handler that calls next and logs a masked copy of each exchange.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		// capture the start of the request body, leaving the full body for next
+		var reqBody []byte
+		reqBodyOmitted := false
+		if r.Body != nil && r.Body != http.NoBody {
+			var err error
+			reqBody, err = io.ReadAll(io.LimitReader(r.Body, int64(m.maxBodySize)+1))
+			// the bytes read before an error are still replayed to next, which receives the error in turn
+			reqBodyOmitted = err != nil || len(reqBody) > m.maxBodySize
+			r.Body = readCloser{
+				Reader: io.MultiReader(bytes.NewReader(reqBody), r.Body),
+				Closer: r.Body,
+			}
+		}
+
+		rec := &responseRecorder{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+			maxBodySize:    m.maxBodySize,
+		}
+		next.ServeHTTP(rec, r)
+
+		e := &Exchange{
+			Method:         r.Method,
+			URL:            m.maskURL(r.URL),
+			RequestHeader:  m.maskHeader(r.Header),
+			StatusCode:     rec.statusCode,
+			ResponseHeader: m.maskHeader(w.Header()),
+			Duration:       time.Since(start),
+		}
+		if !reqBodyOmitted {
+			e.RequestBody = m.bodyMasker.mask(r.Header.Get("Content-Type"), reqBody)
+		}
+		if !rec.truncated {
+			e.ResponseBody = m.bodyMasker.mask(w.Header().Get("Content-Type"), rec.body.Bytes())
+		}
+		m.logger.LogExchange(r.Context(), e)
+	})
+}
+
+// maskHeader returns a copy of h with sensitive values masked.
+func (m *Middleware) maskHeader(h http.Header) http.Header {
+	masked := h.Clone()
+	if err := m.registry.MaskHeader(masked, m.headers); err != nil {
+		// fail closed by redacting every header selected by the policy
+		for name, vals := range masked {
+			if _, found := m.headers.Match(name); found {
+				for i := range vals {
+					vals[i] = redacted
+				}
+			}
+		}
+	}
+	return masked
+}
+
+// maskURL returns u as a string with sensitive values masked.
+func (m *Middleware) maskURL(u *url.URL) string {
+	masked := *u
+	if err := m.registry.MaskURL(&masked, m.url); err != nil {
+		// fail closed, since even the path may hold sensitive segments
+		return redacted
+	}
+	return masked.String()
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// responseRecorder captures the status code and start of the body written to a ResponseWriter.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+	maxBodySize int
+	truncated   bool
+}
+
+func (rec *responseRecorder) WriteHeader(statusCode int) {
+	if !rec.wroteHeader {
+		rec.statusCode = statusCode
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	if remaining := rec.maxBodySize - rec.body.Len(); len(b) > remaining {
+		rec.body.Write(b[:remaining])
+		rec.truncated = true
+	} else {
+		rec.body.Write(b)
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// Flush sends any buffered data to the client if the underlying ResponseWriter is an http.Flusher.
+func (rec *responseRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		rec.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack takes over the connection if the underlying ResponseWriter is an http.Hijacker. The response
+// logged for a hijacked connection is whatever was written before the connection was taken over.
+func (rec *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errorf("hijack: %w", http.ErrNotSupported)
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying ResponseWriter for use with http.ResponseController.
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,219 @@
+package httpmask_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/dgravesa/go-mask/masking/httpmask"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMiddleware(t *testing.T, cfg httpmask.Config) (*httpmask.Middleware, *[]*httpmask.Exchange) {
+	var exchanges []*httpmask.Exchange
+	cfg.Logger = httpmask.LoggerFunc(func(_ context.Context, e *httpmask.Exchange) {
+		exchanges = append(exchanges, e)
+	})
+	m, err := httpmask.New(cfg)
+	assert.NoError(t, err)
+	return m, &exchanges
+}
+
+func Test_Middleware_WithJSONBodies_LogsMaskedBodies(t *testing.T) {
+	// arrange
+	m, exchanges := newTestMiddleware(t, httpmask.Config{
+		BodyPaths: []httpmask.PathRule{
+			{Path: "$.cards[*].number", Mask: "X,showback=4"},
+		},
+	})
+	var handlerBody string
+	handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		handlerBody = string(b)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc123")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id":7,"token":"secret-token"}`)
+	}))
+	reqBody := `{"user":"jsmith","password":"hunter2","cards":[{"number":"4111111111111111"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/login?api_key=abc&page=2", strings.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer abc.def")
+	rec := httptest.NewRecorder()
+
+	// act
+	handler.ServeHTTP(rec, req)
+
+	// assert
+	assert.Equal(t, reqBody, handlerBody)
+	assert.Equal(t, `{"id":7,"token":"secret-token"}`, rec.Body.String())
+	assert.Len(t, *exchanges, 1)
+	e := (*exchanges)[0]
+	assert.Equal(t, http.MethodPost, e.Method)
+	assert.Equal(t, "/login?api_key=***&page=2", e.URL)
+	assert.Equal(t, "**************", e.RequestHeader.Get("Authorization"))
+	assert.JSONEq(t, `{"user":"jsmith","password":"*******","cards":[{"number":"XXXXXXXXXXXX1111"}]}`,
+		string(e.RequestBody))
+	assert.Equal(t, http.StatusCreated, e.StatusCode)
+	assert.Equal(t, "**************", e.ResponseHeader.Get("Set-Cookie"))
+	assert.JSONEq(t, `{"id":7,"token":"************"}`, string(e.ResponseBody))
+	assert.Equal(t, "Bearer abc.def", req.Header.Get("Authorization"))
+}
+
+func Test_Middleware_WithFormBody_LogsMaskedBody(t *testing.T) {
+	// arrange
+	m, exchanges := newTestMiddleware(t, httpmask.Config{})
+	handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		io.WriteString(w, r.PostForm.Get("password"))
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader("password=hunter2&user=jsmith"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+
+	// act
+	handler.ServeHTTP(rec, req)
+
+	// assert
+	assert.Equal(t, "hunter2", rec.Body.String())
+	assert.Equal(t, "password=%2A%2A%2A%2A%2A%2A%2A&user=jsmith", string((*exchanges)[0].RequestBody))
+	assert.Nil(t, (*exchanges)[0].ResponseBody)
+}
+
+func Test_Middleware_WithOversizedBody_OmitsBody(t *testing.T) {
+	// arrange
+	m, exchanges := newTestMiddleware(t, httpmask.Config{
+		MaxBodySize: 8,
+	})
+	var handlerBody string
+	handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		handlerBody = string(b)
+	}))
+	reqBody := `{"password":"hunter2"}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// act
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// assert
+	assert.Equal(t, reqBody, handlerBody)
+	assert.Nil(t, (*exchanges)[0].RequestBody)
+}
+
+func Test_New_WithInvalidConfig_ReturnsError(t *testing.T) {
+	// arrange
+	logger := httpmask.LoggerFunc(func(context.Context, *httpmask.Exchange) {})
+	testCases := map[string]httpmask.Config{
+		"no logger": {},
+		"invalid path": {
+			Logger:    logger,
+			BodyPaths: []httpmask.PathRule{{Path: "users[0]", Mask: "X"}},
+		},
+		"invalid selector": {
+			Logger:    logger,
+			BodyPaths: []httpmask.PathRule{{Path: "$.users[x]", Mask: "X"}},
+		},
+		"invalid url segment pattern": {
+			Logger: logger,
+			URL:    &masking.URLPolicy{PathSegments: []masking.SegmentRule{{Pattern: "[0-9", Mask: "X"}}},
+		},
+		"unknown url masker": {
+			Logger: logger,
+			URL:    &masking.URLPolicy{PathSegments: []masking.SegmentRule{{Pattern: "[0-9]+", Mask: "idk"}}},
+		},
+	}
+
+	for name, cfg := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// act
+			_, err := httpmask.New(cfg)
+
+			// assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_Middleware_WithBodyReadError_ReplaysBytesReadAndOmitsBody(t *testing.T) {
+	// arrange
+	m, exchanges := newTestMiddleware(t, httpmask.Config{})
+	var handlerBody string
+	var handlerErr error
+	handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		handlerBody, handlerErr = string(b), err
+	}))
+	body := io.MultiReader(strings.NewReader(`{"password":`), iotest.ErrReader(errors.New("connection reset")))
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", "application/json")
+
+	// act
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// assert
+	assert.Equal(t, `{"password":`, handlerBody)
+	assert.EqualError(t, handlerErr, "connection reset")
+	assert.Nil(t, (*exchanges)[0].RequestBody)
+}
+
+func Test_Middleware_WithFlush_FlushesUnderlyingWriterAndLogsBody(t *testing.T) {
+	// arrange
+	m, exchanges := newTestMiddleware(t, httpmask.Config{})
+	handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"token":"abc"}`))
+		w.(http.Flusher).Flush()
+	}))
+	rec := httptest.NewRecorder()
+
+	// act
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// assert
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, `{"token":"***"}`, string((*exchanges)[0].ResponseBody))
+}
+
+func Test_Middleware_WithHijackUnsupported_ReturnsErrNotSupported(t *testing.T) {
+	// arrange
+	m, _ := newTestMiddleware(t, httpmask.Config{})
+	var hijackErr error
+	handler := m.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, hijackErr = w.(http.Hijacker).Hijack()
+	}))
+
+	// act
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// assert
+	assert.ErrorIs(t, hijackErr, http.ErrNotSupported)
+}
+
+func Test_Middleware_WithURLMaskerError_LogsRedactedURL(t *testing.T) {
+	// arrange
+	r := masking.NewRegistry()
+	err := masking.RegisterMaskerIn(r, "fail", func(string) (string, error) {
+		return "", errors.New("unavailable")
+	})
+	assert.NoError(t, err)
+	m, exchanges := newTestMiddleware(t, httpmask.Config{
+		URL:      &masking.URLPolicy{PathSegments: []masking.SegmentRule{{Pattern: "[0-9]{6,}", Mask: "fail"}}},
+		Registry: r,
+	})
+	handler := m.Handler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/users/4111111111111111", nil)
+
+	// act
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// assert
+	assert.Equal(t, "[REDACTED]", (*exchanges)[0].URL)
+}
@@ -0,0 +1,189 @@
+package masking
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PathRule associates a path within a JSON document with a masker tag.
+//
+// Paths use a subset of JSONPath: a leading "$" followed by any number of ".name", "['name']", ".*",
+// "[n]" or "[*]" steps, such as "$.users[*].ssn". When a path selects an object or array, the masker
+// is applied to every value nested within it.
+type PathRule struct {
+	Path string
+	Mask string
+}
+
+// JSONPolicy selects the values of JSON documents to mask, by key and by path.
+type JSONPolicy struct {
+	// Keys selects maskers for object values by key, at any depth.
+	Keys KeyPolicy
+	// Paths selects maskers for values by path. Path rules are applied before key rules.
+	Paths []PathRule
+}
+
+// Validate checks that the paths of p are well formed.
+func (p JSONPolicy) Validate() error {
+	for _, rule := range p.Paths {
+		if _, err := parsePath(rule.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type pathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// MaskJSON returns a masked copy of the JSON document data.
+//
+// Numbers are masked by their JSON representation and written as strings once masked. Object keys are
+// written in sorted order.
+func MaskJSON(data []byte, policy JSONPolicy) ([]byte, error) {
+	return DefaultRegistry.MaskJSON(data, policy)
+}
+
+// MaskJSON returns a masked copy of the JSON document data using the maskers of r.
+func (r *Registry) MaskJSON(data []byte, policy JSONPolicy) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	v, err := r.MaskJSONValue(v, policy)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// MaskJSONValue applies masking to a decoded JSON value, such as the result of unmarshaling into an
+// interface{}, and returns the updated value. Objects must be decoded as map[string]interface{} and
+// arrays as []interface{}, as done by encoding/json and gopkg.in/yaml.v3.
+//
+// Objects and arrays are masked in place, but the returned value must be used, since a path rule may
+// replace v itself.
+func MaskJSONValue(v interface{}, policy JSONPolicy) (interface{}, error) {
+	return DefaultRegistry.MaskJSONValue(v, policy)
+}
+
+// MaskJSONValue applies masking to a decoded JSON value using the maskers of r.
+func (r *Registry) MaskJSONValue(v interface{}, policy JSONPolicy) (interface{}, error) {
+	for _, rule := range policy.Paths {
+		steps, err := parsePath(rule.Path)
+		if err != nil {
+			return nil, err
+		}
+		maskFunc, err := r.getMaskFunc(rule.Mask)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rule.Path, err)
+		}
+		v, err = maskPath(v, steps, maskFunc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rule.Path, err)
+		}
+	}
+	if err := r.maskKeys(v, policy.Keys, ""); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// maskPath applies maskFunc to values of v selected by steps and returns the updated v.
+func maskPath(v interface{}, steps []pathStep, maskFunc maskFunc) (interface{}, error) {
+	if len(steps) == 0 {
+		return maskAllValues(v, maskFunc, "")
+	}
+
+	step := steps[0]
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if step.isIndex {
+			return v, nil
+		}
+		for key, item := range val {
+			if !step.wildcard && key != step.key {
+				continue
+			}
+			masked, err := maskPath(item, steps[1:], maskFunc)
+			if err != nil {
+				return nil, err
+			}
+			val[key] = masked
+		}
+	case []interface{}:
+		if !step.isIndex && !step.wildcard {
+			return v, nil
+		}
+		for i, item := range val {
+			if !step.wildcard && i != step.index {
+				continue
+			}
+			masked, err := maskPath(item, steps[1:], maskFunc)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = masked
+		}
+	}
+	return v, nil
+}
+
+func parsePath(path string) ([]pathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("mask: path %s: must begin with \"$\"", path)
+	}
+
+	var steps []pathStep
+	rest := path[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "."):
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("mask: path %s: empty name", path)
+			}
+			steps = append(steps, pathStep{key: name, wildcard: name == "*"})
+			rest = rest[end+1:]
+
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("mask: path %s: missing \"]\"", path)
+			}
+			selector := rest[1:end]
+			switch {
+			case selector == "*":
+				steps = append(steps, pathStep{wildcard: true})
+			case len(selector) >= 2 && selector[0] == '\'' && selector[len(selector)-1] == '\'':
+				steps = append(steps, pathStep{key: selector[1 : len(selector)-1]})
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("mask: path %s: invalid selector \"%s\"", path, selector)
+				}
+				steps = append(steps, pathStep{index: index, isIndex: true})
+			}
+			rest = rest[end+1:]
+
+		default:
+			return nil, fmt.Errorf("mask: path %s: unexpected \"%s\"", path, rest)
+		}
+	}
+
+	return steps, nil
+}
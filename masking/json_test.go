@@ -0,0 +1,127 @@
+package masking_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dgravesa/go-mask/masking"
+)
+
+func Test_MaskJSON_WithKeysAndPaths_MasksSelectedValues(t *testing.T) {
+	// arrange
+	data := []byte(`{
+		"users": [
+			{"name": "Jane", "ssn": "123-45-6789", "pin": 1234, "password": "hunter2"},
+			{"name": "John", "ssn": "987-65-4321", "pin": 98765, "password": null}
+		],
+		"count": 2
+	}`)
+	policy := masking.JSONPolicy{
+		Keys: masking.KeyPolicy{
+			Rules: []masking.KeyRule{
+				{Key: "password", Mask: "*"},
+				{Key: "pin", Mask: "X"},
+			},
+		},
+		Paths: []masking.PathRule{
+			{Path: "$.users[*].ssn", Mask: "X,showback=4"},
+		},
+	}
+
+	// act
+	masked, err := masking.MaskJSON(data, policy)
+
+	// assert
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"users": [
+			{"name": "Jane", "ssn": "XXXXXXX6789", "pin": "XXXX", "password": "*******"},
+			{"name": "John", "ssn": "XXXXXXX4321", "pin": "XXXXX", "password": null}
+		],
+		"count": 2
+	}`, string(masked))
+}
+
+func Test_MaskJSON_WithPathToContainer_MasksAllNestedValues(t *testing.T) {
+	// arrange
+	data := []byte(`{"card": {"number": "4111111111111111", "expiry": "12/30", "cvv": 123}, "id": "c-1"}`)
+	policy := masking.JSONPolicy{
+		Paths: []masking.PathRule{{Path: "$['card']", Mask: "x"}},
+	}
+
+	// act
+	masked, err := masking.MaskJSON(data, policy)
+
+	// assert
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"card": {"number": "xxxxxxxxxxxxxxxx", "expiry": "xxxxx", "cvv": "xxx"}, "id": "c-1"}`,
+		string(masked))
+}
+
+func Test_MaskJSONValue_WithRootPath_ReplacesValue(t *testing.T) {
+	// arrange
+	var v interface{} = "secret"
+	policy := masking.JSONPolicy{
+		Paths: []masking.PathRule{{Path: "$", Mask: "*"}},
+	}
+
+	// act
+	masked, err := masking.MaskJSONValue(v, policy)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "******", masked)
+}
+
+func Test_JSONPolicy_Validate_WithInvalidPaths_ReturnsError(t *testing.T) {
+	// arrange
+	testCases := map[string]string{
+		"missing root":     "users[0]",
+		"empty name":       "$.users..ssn",
+		"unclosed bracket": "$.users[0",
+		"invalid selector": "$.users[x]",
+	}
+
+	for name, path := range testCases {
+		t.Run(name, func(t *testing.T) {
+			policy := masking.JSONPolicy{
+				Paths: []masking.PathRule{{Path: path, Mask: "X"}},
+			}
+
+			// act
+			err := policy.Validate()
+
+			// assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_MaskJSON_WithUnknownMasker_ReturnsError(t *testing.T) {
+	// arrange
+	data := []byte(`{"password": "hunter2"}`)
+	policy := masking.JSONPolicy{
+		Keys: masking.KeyPolicy{
+			Rules: []masking.KeyRule{{Key: "password", Mask: "unregistered"}},
+		},
+	}
+
+	// act
+	_, err := masking.MaskJSON(data, policy)
+
+	// assert
+	assert.Error(t, err)
+}
+
+func Test_MaskJSON_WithKeyRuleOnNumbersAndBools_MasksStringRepresentation(t *testing.T) {
+	// arrange
+	data := []byte(`{"token_expires_in":3600,"secret":true,"id":7}`)
+
+	// act
+	masked, err := masking.MaskJSON(data, masking.JSONPolicy{Keys: masking.DefaultKeyPolicy})
+
+	// assert
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"token_expires_in":"****","secret":"****","id":7}`, string(masked))
+}
@@ -1,8 +1,11 @@
 package masking
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"unsafe"
 )
 
 // Mask applies masking to public fields of v based on struct tagging.
@@ -10,16 +13,62 @@ import (
 // Mask will apply masking to any non-pointer and non-slice fields of the struct and its nested
 // structs. In cases where pointers or slices of the struct should also be masked, use DeepMask
 // instead.
-func Mask(v interface{}) error {
-	return mask(reflect.ValueOf(v), false)
+//
+// Options apply to this call only, as for MaskWith.
+func Mask(v interface{}, opts ...Option) error {
+	return DefaultRegistry.Mask(v, opts...)
 }
 
 // DeepMask applies masking to all public fields of v, including pointers and slices, based on struct tagging.
-func DeepMask(v interface{}) error {
-	return mask(reflect.ValueOf(v), true)
+//
+// DeepMask is the same as MaskWith with the options Pointers(true) and Slices(true) given first.
+func DeepMask(v interface{}, opts ...Option) error {
+	return DefaultRegistry.DeepMask(v, opts...)
+}
+
+// MaskWith applies masking to v based on struct tagging, with the traversal configured by opts.
+//
+// Without options, MaskWith masks as Mask does. Options are applied in order, so later options take
+// precedence over earlier ones:
+//
+//	err := masking.MaskWith(&v,
+//		masking.Pointers(true),
+//		masking.Maps(true),
+//		masking.MaxDepth(8),
+//		masking.FailClosed(true),
+//		masking.WithRegistry(r))
+func MaskWith(v interface{}, opts ...Option) error {
+	return DefaultRegistry.Mask(v, opts...)
+}
+
+// MaskContext applies masking to public fields of v as Mask does, passing ctx to context-aware maskers.
+//
+// Masking stops once ctx is done, returning the error of ctx wrapped with the path of the field being
+// masked, such as "mask: Orders[3].Card: context canceled".
+func MaskContext(ctx context.Context, v interface{}, opts ...Option) error {
+	return DefaultRegistry.MaskContext(ctx, v, opts...)
+}
+
+// DeepMaskContext applies masking to all public fields of v, including pointers and slices, as DeepMask
+// does, passing ctx to context-aware maskers.
+//
+// See MaskContext for details on cancellation.
+func DeepMaskContext(ctx context.Context, v interface{}, opts ...Option) error {
+	return DefaultRegistry.DeepMaskContext(ctx, v, opts...)
+}
+
+// MaskValue applies the masker given by maskTag, such as "X,showback=4", to the value pointed to by ptr.
+func MaskValue(ptr interface{}, maskTag string) error {
+	return DefaultRegistry.MaskValue(ptr, maskTag)
 }
 
-func mask(ptr reflect.Value, maskPointedVals bool) error {
+// mask applies masking to the value pointed to by ptr, where path is the field path of the value
+// relative to the masked argument.
+func (r *Registry) mask(ctx context.Context, ptr reflect.Value, path string, opts maskOptions) error {
+	if err := ctx.Err(); err != nil {
+		return pathError(path, err)
+	}
+
 	ptrKind := ptr.Kind()
 	if ptrKind != reflect.Pointer && ptrKind != reflect.Interface {
 		return fmt.Errorf("mask: expected pointer or interface argument")
@@ -28,52 +77,277 @@ func mask(ptr reflect.Value, maskPointedVals bool) error {
 	val := ptr.Elem()
 	valKind := val.Kind()
 
+	if !opts.excluded {
+		// within an excluded field, only included fields are masked
+		if typeMaskFunc, found := r.typeMaskerFor(ptr); found {
+			// apply type masker in place of traversal
+			entry := opts.reportEntry(path, val, "type:"+val.Type().String())
+			err := maskError(path, typeMaskFunc(ctx, ptr))
+			opts.recordResult(entry, err)
+			return err
+		}
+		entry := opts.reportEntry(path, val, "self")
+		if isSelfMasked, err := maskSelf(ptr); isSelfMasked {
+			// apply the value's own masking in place of traversal
+			opts.recordResult(entry, err)
+			return err
+		}
+	}
+
+	switch valKind {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		if opts.maxDepth > 0 && opts.depth >= opts.maxDepth {
+			err := pathError(path, fmt.Errorf("exceeds max depth %d", opts.maxDepth))
+			opts.recordResult(opts.reportEntry(path, val, ""), err)
+			return err
+		}
+	}
+	childOpts := opts
+	childOpts.depth++
+
 	switch valKind {
 	case reflect.Struct:
 		t := val.Type()
+		if t.Implements(secretType) {
+			// the value held by a Secret is left as is, even when masking unexported fields
+			break
+		}
+		var fieldCtx context.Context
 		for i := 0; i < t.NumField(); i++ {
-			fieldPtr, isValPointer := getPointer(val.Field(i))
-			if isValPointer && !maskPointedVals {
+			fieldPath := joinKeyPath(path, t.Field(i).Name)
+			field := val.Field(i)
+			if !t.Field(i).IsExported() {
+				// only public fields are masked by default
+				if !opts.maskUnexported || !field.CanAddr() {
+					continue
+				}
+				field = reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+			}
+
+			fieldPtr, isValPointer := getPointer(field)
+			if isValPointer && !opts.followPointers {
+				if !field.IsNil() {
+					opts.recordSkip(fieldPath, field, "", ReasonPointerNotFollowed)
+				}
+				continue
+			}
+
+			fieldMaskTag, excluded, err := r.selectFieldMaskTag(t, i, fieldPath, opts)
+			if excluded && len(opts.includes) == 0 {
+				opts.recordSkip(fieldPath, field, "", ReasonExcluded)
 				continue
 			}
 
-			if fieldMaskTag := t.Field(i).Tag.Get("mask"); fieldMaskTag != "" {
+			switch {
+			case err != nil:
+				// the masker tag of the field could not be determined
+				opts.recordResult(opts.reportEntry(fieldPath, field, ""), err)
+			case excluded:
+				// traverse the excluded field for any included fields nested within it
+				excludedOpts := childOpts
+				excludedOpts.excluded = true
+				err = r.mask(ctx, fieldPtr, fieldPath, excludedOpts)
+			case fieldMaskTag != "" && opts.includesStruct(fieldPath, field.Type()):
+				// apply the include to the string fields nested within the included field
+				includedOpts := childOpts
+				includedOpts.excluded = false
+				includedOpts.included = fieldMaskTag
+				err = r.mask(ctx, fieldPtr, fieldPath, includedOpts)
+			case fieldMaskTag != "":
 				// apply masking if tag is specified
-				maskFieldFunc, err := getMaskFunc(fieldMaskTag)
-				if err != nil {
-					return err
-				}
-				err = maskFieldFunc(fieldPtr)
-				if err != nil {
-					return err
+				if fieldCtx == nil {
+					fieldCtx = withParent(ctx, val)
 				}
-			} else {
+				err = r.maskField(fieldCtx, val, fieldPtr, fieldPath, fieldMaskTag, opts)
+			default:
 				// perform masking recursively
-				err := mask(fieldPtr, maskPointedVals)
-				if err != nil {
+				err = r.mask(ctx, fieldPtr, fieldPath, childOpts)
+			}
+			if err != nil {
+				if err := opts.failClosed(ctx, field, err); err != nil {
 					return err
 				}
 			}
 		}
 
 	case reflect.Slice, reflect.Array:
-		if maskPointedVals || valKind == reflect.Array {
+		if !opts.maskSlices && valKind == reflect.Slice {
+			if val.Len() > 0 {
+				opts.recordSkip(path, val, "", ReasonSliceNotFollowed)
+			}
+		} else {
 			for i := 0; i < val.Len(); i++ {
-				itemPtr, isValPointer := getPointer(val.Index(i))
-				if isValPointer && !maskPointedVals {
+				item := val.Index(i)
+				itemPath := fmt.Sprintf("%s[%d]", path, i)
+				itemPtr, isValPointer := getPointer(item)
+				if isValPointer && !opts.followPointers {
+					if !item.IsNil() {
+						opts.recordSkip(itemPath, item, "", ReasonPointerNotFollowed)
+					}
 					continue
 				}
-				err := mask(itemPtr, maskPointedVals)
+				err := r.mask(ctx, itemPtr, itemPath, childOpts)
 				if err != nil {
+					if err := opts.failClosed(ctx, item, err); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+	case reflect.Map:
+		if !opts.maskMaps {
+			if val.Len() > 0 {
+				opts.recordSkip(path, val, "", ReasonMapNotFollowed)
+			}
+		} else {
+			iter := val.MapRange()
+			for iter.Next() {
+				if err := r.maskMapValue(ctx, val, iter.Key(), iter.Value(), path, childOpts); err != nil {
 					return err
 				}
 			}
 		}
+
+	case reflect.Interface:
+		if !opts.maskInterfaces && !val.IsNil() {
+			opts.recordSkip(path, val, "", ReasonInterfaceNotFollowed)
+		} else if !val.IsNil() {
+			if !val.CanSet() {
+				return pathError(path, fmt.Errorf("cannot set value held by interface"))
+			}
+			return r.maskHeldValue(ctx, val.Elem(), path, opts, val.Set)
+		}
+	}
+
+	return nil
+}
+
+// maskField applies masking with maskTag to the field pointed to by fieldPtr of the struct value
+// parent, if the conditions of maskTag hold.
+func (r *Registry) maskField(
+	ctx context.Context, parent, fieldPtr reflect.Value, fieldPath, maskTag string, opts maskOptions,
+) error {
+	entry := opts.reportEntry(fieldPath, fieldPtr.Elem(), maskTag)
+	fieldMasker, err := r.getFieldMasker(maskTag)
+	if err != nil {
+		opts.recordResult(entry, err)
+		return err
+	}
+	applies, err := fieldMasker.applies(parent)
+	if err != nil {
+		opts.recordResult(entry, err)
+		return err
 	}
+	if !applies {
+		// a field whose conditions do not hold is left as is
+		opts.recordSkip(fieldPath, fieldPtr.Elem(), maskTag, ReasonConditionNotMet)
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return pathError(fieldPath, err)
+	}
+	if err = fieldMasker.maskFunc(ctx, fieldPtr); err != nil {
+		err = pathError(fieldPath, err)
+	}
+	opts.recordResult(entry, err)
+	return err
+}
 
+// maskMapValue applies masking to the value of key within map m.
+func (r *Registry) maskMapValue(
+	ctx context.Context, m, key, val reflect.Value, path string, opts maskOptions,
+) error {
+	valPath := fmt.Sprintf("%s[%v]", path, key)
+	if opts.report != nil {
+		// keys may themselves be sensitive, such as email addresses, so are left out of reports
+		valPath = path + "[*]"
+	}
+	err := r.maskHeldValue(ctx, val, valPath, opts, func(masked reflect.Value) {
+		m.SetMapIndex(key, masked)
+	})
+	if err != nil {
+		if err := opts.failClosed(ctx, reflect.Value{}, err); err != nil {
+			return err
+		}
+		// a map value cannot be zeroed in place, so it is replaced by the zero value
+		m.SetMapIndex(key, reflect.Zero(val.Type()))
+	}
 	return nil
 }
 
+// maskHeldValue applies masking to val, which is not addressable, such as a map value or the value
+// held by an interface. Pointers are masked in place, while other values are masked as a copy that is
+// passed to set.
+func (r *Registry) maskHeldValue(
+	ctx context.Context, val reflect.Value, path string, opts maskOptions, set func(reflect.Value),
+) error {
+	if val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() == reflect.Pointer {
+		if !opts.followPointers && !val.IsNil() {
+			opts.recordSkip(path, val, "", ReasonPointerNotFollowed)
+		}
+		if !opts.followPointers || val.IsNil() {
+			return nil
+		}
+		return r.mask(ctx, val, path, opts)
+	}
+
+	ptr := copyOf(val)
+	if err := r.mask(ctx, ptr, path, opts); err != nil {
+		return err
+	}
+	set(ptr.Elem())
+	return nil
+}
+
+// selectFieldMaskTag returns the masker tag for the ith field of struct type t at fieldPath, or true if
+// the field is excluded from masking.
+//
+// Includes of opts take precedence, followed by excludes and then fieldMaskTag. Within an included
+// field, string fields are masked by the include in place of their tags.
+func (r *Registry) selectFieldMaskTag(
+	t reflect.Type, i int, fieldPath string, opts maskOptions,
+) (string, bool, error) {
+	if opts.selectsFields() {
+		if maskTag, excluded := opts.selectField(fieldPath); maskTag != "" || excluded {
+			return maskTag, excluded, nil
+		}
+	}
+	if opts.included != "" {
+		if t.Field(i).Type.Kind() == reflect.String {
+			return opts.included, false, nil
+		}
+		return "", false, nil
+	}
+	if opts.excluded {
+		return "", true, nil
+	}
+	// an empty profile tag reveals the field as is to the profile
+	return r.fieldMaskTag(t, i, opts.tagKeys, opts.profile)
+}
+
+// maskError wraps err with path if err is due to a done context, and otherwise returns err as is.
+func maskError(path string, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return pathError(path, err)
+	}
+	return err
+}
+
+// pathError wraps err with path, the field path of the value being masked.
+func pathError(path string, err error) error {
+	if path == "" {
+		return fmt.Errorf("mask: %w", err)
+	}
+	return fmt.Errorf("mask: %s: %w", path, err)
+}
+
 // getPointer returns val and true if val is a pointer, otherwise a pointer to val and false.
 func getPointer(val reflect.Value) (reflect.Value, bool) {
 	if val.Kind() == reflect.Pointer {
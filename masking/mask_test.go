@@ -1,6 +1,9 @@
 package masking_test
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/dgravesa/go-mask/masking"
@@ -441,3 +444,98 @@ func Test_Mask_UnrecognizedMaskFunc_ReturnsError(t *testing.T) {
 	// assert
 	assert.Error(t, err)
 }
+
+func Test_MaskValue_WithMaskTag_MasksValue(t *testing.T) {
+	// arrange
+	s := "1234567890"
+
+	// act
+	err := masking.MaskValue(&s, "X,showback=4")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "XXXXXX7890", s)
+}
+
+func Test_MaskValue_OnNonPointer_ReturnsError(t *testing.T) {
+	// act
+	err := masking.MaskValue("1234567890", "X")
+
+	// assert
+	assert.Error(t, err)
+}
+
+type tenantKey struct{}
+
+func Test_MaskContext_WithContextMasker_PassesContextToMasker(t *testing.T) {
+	// arrange
+	r := masking.NewRegistry()
+	err := masking.RegisterMaskerIn(r, "tenant", func(ctx context.Context, s string) (string, error) {
+		return ctx.Value(tenantKey{}).(string) + ":" + strings.Repeat("X", len(s)), nil
+	})
+	assert.NoError(t, err)
+	type Account struct {
+		Email string `mask:"tenant"`
+	}
+	account := Account{Email: "j@x.io"}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+	// act
+	err = r.MaskContext(ctx, &account)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "acme:XXXXXX", account.Email)
+}
+
+func Test_DeepMaskContext_WithCanceledContext_ReturnsErrorWithFieldPath(t *testing.T) {
+	// arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	r := masking.NewRegistry()
+	err := masking.RegisterMaskerIn(r, "cancel", func(_ context.Context, s *string, _ ...string) error {
+		*s = "XXXX"
+		cancel()
+		return nil
+	})
+	assert.NoError(t, err)
+	type Card struct {
+		Number string `mask:"cancel"`
+		CVV    string `mask:"*"`
+	}
+	type Order struct {
+		Cards []Card
+	}
+	order := Order{Cards: []Card{{Number: "4111", CVV: "123"}}}
+
+	// act
+	err = r.DeepMaskContext(ctx, &order)
+
+	// assert
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "mask: Cards[0].CVV: context canceled", err.Error())
+	assert.Equal(t, "123", order.Cards[0].CVV)
+}
+
+func Test_MaskContext_WithMaskerReturningContextError_ReturnsErrorWithFieldPath(t *testing.T) {
+	// arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	r := masking.NewRegistry()
+	err := masking.RegisterMaskerIn(r, "slow", func(ctx context.Context, v interface{}, _ ...string) error {
+		cancel()
+		return ctx.Err()
+	})
+	assert.NoError(t, err)
+	type Blob struct {
+		Data []byte `mask:"slow"`
+	}
+	type Upload struct {
+		Blob Blob
+	}
+
+	// act
+	err = r.MaskContext(ctx, &Upload{})
+
+	// assert
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, "mask: Blob.Data: context canceled", err.Error())
+}
@@ -1,10 +1,19 @@
 package masking
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 )
 
+// Masker is the set of masker function signatures that may be registered with RegisterMasker.
+//
+// Maskers that take a context.Context receive the context given to MaskContext, DeepMaskContext,
+// MaskFor or DeepMaskFor, or context.Background otherwise. They may use it to read request-scoped
+// values, such as the caller's tenant or a hashing salt, and should return ctx.Err() once ctx is done.
+//
+// Maskers that take a parent receive a pointer to the struct holding the masked field, or nil if the
+// masked value is not a struct field.
 type Masker interface {
 	func(input string) (output string) |
 		func(input string) (output string, err error) |
@@ -13,11 +22,21 @@ type Masker interface {
 		func(s *string) (err error) |
 		func(s *string, args ...string) (err error) |
 		func(v interface{}) (err error) |
-		func(v interface{}, args ...string) (err error)
+		func(v interface{}, args ...string) (err error) |
+		func(v interface{}, parent interface{}, args ...string) (err error) |
+		func(ctx context.Context, input string) (output string, err error) |
+		func(ctx context.Context, input string, args ...string) (output string, err error) |
+		func(ctx context.Context, s *string, args ...string) (err error) |
+		func(ctx context.Context, v interface{}, args ...string) (err error)
 }
 
 // RegisterMasker registers a new masker function for use in struct tagging.
 func RegisterMasker[M Masker](name string, masker M) error {
+	return RegisterMaskerIn(DefaultRegistry, name, masker)
+}
+
+// RegisterMaskerIn registers a new masker function with r for use in struct tagging.
+func RegisterMaskerIn[M Masker](r *Registry, name string, masker M) error {
 	var mfb maskFuncBuilder
 
 	switch m := reflect.ValueOf(masker).Interface().(type) {
@@ -55,9 +74,27 @@ func RegisterMasker[M Masker](name string, masker M) error {
 		})
 	case func(interface{}, ...string) error:
 		mfb = createStructMaskFuncBuilder(name, m)
+	case func(interface{}, interface{}, ...string) error:
+		mfb = createParentStructMaskFuncBuilder(name, m)
+	case func(context.Context, string) (string, error):
+		mfb = createContextStringMaskFuncBuilder(name, func(ctx context.Context, s *string, _ ...string) error {
+			var err error
+			*s, err = m(ctx, *s)
+			return err
+		})
+	case func(context.Context, string, ...string) (string, error):
+		mfb = createContextStringMaskFuncBuilder(name, func(ctx context.Context, s *string, args ...string) error {
+			var err error
+			*s, err = m(ctx, *s, args...)
+			return err
+		})
+	case func(context.Context, *string, ...string) error:
+		mfb = createContextStringMaskFuncBuilder(name, m)
+	case func(context.Context, interface{}, ...string) error:
+		mfb = createContextStructMaskFuncBuilder(name, m)
 	default:
 		return fmt.Errorf("unsupported masker signature")
 	}
 
-	return registerMaskFuncBuilder(name, mfb)
+	return r.registerMaskFuncBuilder(name, mfb)
 }
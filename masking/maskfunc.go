@@ -1,30 +1,48 @@
 package masking
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
 )
 
-type maskFunc func(ptr reflect.Value) error
+type maskFunc func(ctx context.Context, ptr reflect.Value) error
 
 type maskFuncBuilder func(args ...string) maskFunc
 
-var maskFuncBuilderRegistry = map[string]maskFuncBuilder{
-	"X":      simpleMaskFuncBuilderWithRune('X'),
-	"x":      simpleMaskFuncBuilderWithRune('x'),
-	"*":      simpleMaskFuncBuilderWithRune('*'),
-	"-":      simpleMaskFuncBuilderWithRune('-'),
-	"_":      simpleMaskFuncBuilderWithRune('_'),
-	".":      simpleMaskFuncBuilderWithRune('.'),
-	"simple": simpleMaskFuncBuilder(),
+func builtinMaskFuncBuilders() map[string]maskFuncBuilder {
+	return map[string]maskFuncBuilder{
+		"X":      simpleMaskFuncBuilderWithRune('X'),
+		"x":      simpleMaskFuncBuilderWithRune('x'),
+		"*":      simpleMaskFuncBuilderWithRune('*'),
+		"-":      simpleMaskFuncBuilderWithRune('-'),
+		"_":      simpleMaskFuncBuilderWithRune('_'),
+		".":      simpleMaskFuncBuilderWithRune('.'),
+		"simple": simpleMaskFuncBuilder(),
+		"self":   selfMaskFuncBuilder(),
+		"regex":  regexMaskFuncBuilder(),
+	}
+}
+
+func (r *Registry) getMaskFunc(tag string) (maskFunc, error) {
+	args := splitMaskTag(tag)
+	for _, arg := range args[1:] {
+		if strings.HasPrefix(arg, "if=") || strings.HasPrefix(arg, "unless=") {
+			return nil, fmt.Errorf("conditions are only supported in struct field tags: \"%s\"", tag)
+		}
+	}
+	return r.buildMaskFunc(args)
 }
 
-func getMaskFunc(tag string) (maskFunc, error) {
-	args := strings.Split(tag, ",")
+// buildMaskFunc returns the mask func given by the masker name and arguments of a tag.
+func (r *Registry) buildMaskFunc(args []string) (maskFunc, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("missing mask func")
+	}
 	funcName := args[0]
 
-	builder, found := maskFuncBuilderRegistry[funcName]
+	builder, found := r.maskFuncBuilders[funcName]
 	if !found {
 		return nil, fmt.Errorf("unrecognized mask func: \"%s\"", funcName)
 	}
@@ -32,23 +50,55 @@ func getMaskFunc(tag string) (maskFunc, error) {
 	return builder(args[1:]...), nil
 }
 
-func registerMaskFuncBuilder(name string, builder maskFuncBuilder) error {
+// splitMaskTag splits a masker tag into the masker name and its arguments at commas. A comma preceded
+// by a backslash is kept within the argument, such as in `regex,pattern=\d{3\,4}`.
+func splitMaskTag(tag string) []string {
+	if !strings.Contains(tag, `\,`) {
+		return strings.Split(tag, ",")
+	}
+
+	var args []string
+	var arg strings.Builder
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			arg.WriteByte(',')
+			i++
+		case tag[i] == ',':
+			args = append(args, arg.String())
+			arg.Reset()
+		default:
+			arg.WriteByte(tag[i])
+		}
+	}
+	return append(args, arg.String())
+}
+
+func (r *Registry) registerMaskFuncBuilder(name string, builder maskFuncBuilder) error {
 	if strings.Contains(name, ",") {
 		return fmt.Errorf("commas not permitted in mask func names")
 	}
 
-	_, found := maskFuncBuilderRegistry[name]
+	_, found := r.maskFuncBuilders[name]
 	if found {
 		return fmt.Errorf("mask func with name already exists: \"%s\"", name)
 	}
 
-	maskFuncBuilderRegistry[name] = builder
+	r.maskFuncBuilders[name] = builder
 	return nil
 }
 
 func createStringMaskFuncBuilder(name string, masker func(*string, ...string) error) maskFuncBuilder {
+	return createContextStringMaskFuncBuilder(name, func(_ context.Context, s *string, args ...string) error {
+		return masker(s, args...)
+	})
+}
+
+func createContextStringMaskFuncBuilder(
+	name string, masker func(context.Context, *string, ...string) error,
+) maskFuncBuilder {
 	return func(args ...string) maskFunc {
-		return func(ptr reflect.Value) error {
+		return func(ctx context.Context, ptr reflect.Value) error {
 			// get the current value
 			val := ptr.Elem()
 			if val.Kind() != reflect.String {
@@ -58,15 +108,23 @@ func createStringMaskFuncBuilder(name string, masker func(*string, ...string) er
 			// mask the string in place
 			var sptr *string // convert to *string to enable type aliases
 			sptr = ptr.Convert(reflect.TypeOf(sptr)).Interface().(*string)
-			return masker(sptr, args...)
+			return masker(ctx, sptr, args...)
 		}
 	}
 }
 
 func createStructMaskFuncBuilder(name string, masker func(interface{}, ...string) error) maskFuncBuilder {
+	return createContextStructMaskFuncBuilder(name, func(_ context.Context, v interface{}, args ...string) error {
+		return masker(v, args...)
+	})
+}
+
+func createContextStructMaskFuncBuilder(
+	name string, masker func(context.Context, interface{}, ...string) error,
+) maskFuncBuilder {
 	return func(args ...string) maskFunc {
-		return func(ptr reflect.Value) error {
-			return masker(ptr.Interface(), args...)
+		return func(ctx context.Context, ptr reflect.Value) error {
+			return masker(ctx, ptr.Interface(), args...)
 		}
 	}
 }
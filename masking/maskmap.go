@@ -0,0 +1,170 @@
+package masking
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// KeyRule associates a key name or glob pattern with a masker tag.
+type KeyRule struct {
+	// Key is a key name or a glob pattern in the syntax of path.Match, such as "*token*".
+	Key string
+	// Mask is the masker tag to apply to values under matching keys, such as "X,showback=4".
+	Mask string
+}
+
+// KeyPolicy selects the masking to apply to unstructured data based on key names.
+//
+// Rules are evaluated in order and the first rule matching a key is applied.
+type KeyPolicy struct {
+	Rules []KeyRule
+	// IgnoreCase enables case-insensitive matching of keys against rules.
+	IgnoreCase bool
+}
+
+// DefaultKeyPolicy masks values under commonly sensitive key names.
+var DefaultKeyPolicy = KeyPolicy{
+	Rules: []KeyRule{
+		{Key: "*password*", Mask: "*"},
+		{Key: "*passwd*", Mask: "*"},
+		{Key: "*secret*", Mask: "*"},
+		{Key: "*token*", Mask: "*"},
+		{Key: "*api_key*", Mask: "*"},
+		{Key: "*apikey*", Mask: "*"},
+		{Key: "*private_key*", Mask: "*"},
+		{Key: "authorization", Mask: "*"},
+		{Key: "cookie", Mask: "*"},
+		{Key: "set-cookie", Mask: "*"},
+		{Key: "ssn", Mask: "X,showback=4"},
+	},
+	IgnoreCase: true,
+}
+
+// Match returns the masker tag of the first rule matching key, if any.
+func (p KeyPolicy) Match(key string) (string, bool) {
+	if p.IgnoreCase {
+		key = strings.ToLower(key)
+	}
+	for _, rule := range p.Rules {
+		pattern := rule.Key
+		if p.IgnoreCase {
+			pattern = strings.ToLower(pattern)
+		}
+		if matched, _ := path.Match(pattern, key); matched {
+			return rule.Mask, true
+		}
+	}
+	return "", false
+}
+
+// MaskMap applies masking to values of m based on their keys.
+//
+// MaskMap recurses through any map[string]interface{} and []interface{} values nested within m, such
+// as those produced by decoding JSON or YAML. When a key matches a rule of policy, the rule's masker
+// is applied to the value under that key, or to every value nested under it if the value is itself a
+// map or slice. Numbers and booleans are masked by their string representation and replaced by the
+// masked string.
+func MaskMap(m map[string]interface{}, policy KeyPolicy) error {
+	return DefaultRegistry.MaskMap(m, policy)
+}
+
+// maskKeys applies masking to values of v under keys matching policy, recursing through nested
+// map[string]interface{} and []interface{} values. Values under matching keys are replaced only once
+// masked.
+func (r *Registry) maskKeys(v interface{}, policy KeyPolicy, parentPath string) error {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, item := range val {
+			keyPath := joinKeyPath(parentPath, key)
+			maskTag, found := policy.Match(key)
+			if !found {
+				if err := r.maskKeys(item, policy, keyPath); err != nil {
+					return err
+				}
+				continue
+			}
+
+			maskFunc, err := r.getMaskFunc(maskTag)
+			if err != nil {
+				return keyPathError(keyPath, err)
+			}
+			masked, err := maskAllValues(item, maskFunc, keyPath)
+			if err != nil {
+				return err
+			}
+			val[key] = masked
+		}
+	case []interface{}:
+		for i, item := range val {
+			if err := r.maskKeys(item, policy, fmt.Sprintf("%s[%d]", parentPath, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// maskAllValues applies maskFunc to val, or to all values nested in val if val is a map or slice.
+func maskAllValues(val interface{}, maskFunc maskFunc, valPath string) (interface{}, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		for key, item := range v {
+			masked, err := maskAllValues(item, maskFunc, joinKeyPath(valPath, key))
+			if err != nil {
+				return nil, err
+			}
+			v[key] = masked
+		}
+		return v, nil
+	case []interface{}:
+		for i, item := range v {
+			masked, err := maskAllValues(item, maskFunc, fmt.Sprintf("%s[%d]", valPath, i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = masked
+		}
+		return v, nil
+	}
+
+	switch reflect.ValueOf(val).Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		// mask strings, numbers and booleans by their string representation, as in JSON documents
+		s := fmt.Sprint(val)
+		if err := maskFunc(context.Background(), reflect.ValueOf(&s)); err != nil {
+			return nil, keyPathError(valPath, err)
+		}
+		return s, nil
+	}
+
+	// mask a copy of the value, since values held in an interface are not addressable
+	ptr := reflect.New(reflect.TypeOf(val))
+	ptr.Elem().Set(reflect.ValueOf(val))
+	if err := maskFunc(context.Background(), ptr); err != nil {
+		return nil, keyPathError(valPath, err)
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+func joinKeyPath(parentPath, key string) string {
+	if parentPath == "" {
+		return key
+	}
+	return parentPath + "." + key
+}
+
+// keyPathError wraps err with path, if any.
+func keyPathError(path string, err error) error {
+	if path == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
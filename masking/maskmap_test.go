@@ -0,0 +1,164 @@
+package masking_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MaskMap_WithKeyRules_MasksMatchingKeys(t *testing.T) {
+	// arrange
+	m := map[string]interface{}{
+		"username":     "jsmith",
+		"password":     "hunter2",
+		"accessToken":  "abcdef123456",
+		"accountId":    "1234567890",
+		"refreshCount": 3.0,
+	}
+	policy := masking.KeyPolicy{
+		Rules: []masking.KeyRule{
+			{Key: "password", Mask: "*"},
+			{Key: "*token*", Mask: "X"},
+			{Key: "accountId", Mask: "X,showback=4"},
+		},
+		IgnoreCase: true,
+	}
+
+	// act
+	err := masking.MaskMap(m, policy)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"username":     "jsmith",
+		"password":     "*******",
+		"accessToken":  "XXXXXXXXXXXX",
+		"accountId":    "XXXXXX7890",
+		"refreshCount": 3.0,
+	}, m)
+}
+
+func Test_MaskMap_WithCaseSensitiveRules_DoesNotMaskOtherCase(t *testing.T) {
+	// arrange
+	m := map[string]interface{}{
+		"Password": "hunter2",
+	}
+	policy := masking.KeyPolicy{
+		Rules: []masking.KeyRule{
+			{Key: "password", Mask: "*"},
+		},
+	}
+
+	// act
+	err := masking.MaskMap(m, policy)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", m["Password"])
+}
+
+func Test_MaskMap_OnDecodedJSON_MasksNestedMapsAndSlices(t *testing.T) {
+	// arrange
+	var m map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"user": {"name": "Jane", "password": "p4ss"},
+		"sessions": [{"id": 1, "token": "abc"}, {"id": 2, "token": "defg"}],
+		"secrets": {"primary": "xyz", "backups": ["12", "345"]}
+	}`), &m)
+	assert.NoError(t, err)
+
+	// act
+	err = masking.MaskMap(m, masking.DefaultKeyPolicy)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"user": map[string]interface{}{"name": "Jane", "password": "****"},
+		"sessions": []interface{}{
+			map[string]interface{}{"id": 1.0, "token": "***"},
+			map[string]interface{}{"id": 2.0, "token": "****"},
+		},
+		"secrets": map[string]interface{}{"primary": "***", "backups": []interface{}{"**", "***"}},
+	}, m)
+}
+
+func Test_MaskMap_WithDefaultKeyPolicy_MasksCompoundPasswordKeys(t *testing.T) {
+	// arrange
+	m := map[string]interface{}{
+		"new_password":     "hunter2",
+		"DB_PASSWORD":      "p4ss",
+		"passwordConfirm":  "hunter2",
+		"password_hint":    "pets",
+		"username":         "jane",
+		"passphrase_label": "home",
+	}
+
+	// act
+	err := masking.MaskMap(m, masking.DefaultKeyPolicy)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"new_password":     "*******",
+		"DB_PASSWORD":      "****",
+		"passwordConfirm":  "*******",
+		"password_hint":    "****",
+		"username":         "jane",
+		"passphrase_label": "home",
+	}, m)
+}
+
+func Test_MaskMap_WithNumberAndBoolValues_MasksStringRepresentation(t *testing.T) {
+	// arrange
+	m := map[string]interface{}{
+		"pin":              1234.0,
+		"token_expires_in": 3600,
+		"secret":           true,
+	}
+
+	// act
+	err := masking.MaskMap(m, masking.DefaultKeyPolicy)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"pin": 1234.0, "token_expires_in": "****", "secret": "****"}, m)
+}
+
+func Test_MaskMap_WithMaskerError_LeavesValueAsIs(t *testing.T) {
+	// arrange
+	r := masking.NewRegistry()
+	registerErr := masking.RegisterMaskerIn(r, "fail", func(s *string, _ ...string) error {
+		return fmt.Errorf("unsupported value")
+	})
+	assert.NoError(t, registerErr)
+	m := map[string]interface{}{"pin": 1234.0}
+	policy := masking.KeyPolicy{Rules: []masking.KeyRule{{Key: "pin", Mask: "fail"}}}
+
+	// act
+	err := r.MaskMap(m, policy)
+
+	// assert
+	assert.Error(t, err)
+	assert.Equal(t, map[string]interface{}{"pin": 1234.0}, m)
+}
+
+func Test_MaskMap_UnrecognizedMaskFunc_ReturnsError(t *testing.T) {
+	// arrange
+	m := map[string]interface{}{
+		"password": "hunter2",
+	}
+	policy := masking.KeyPolicy{
+		Rules: []masking.KeyRule{
+			{Key: "password", Mask: "idk"},
+		},
+	}
+
+	// act
+	err := masking.MaskMap(m, policy)
+
+	// assert
+	assert.Error(t, err)
+}
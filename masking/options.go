@@ -0,0 +1,392 @@
+package masking
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// Option configures a single call to MaskWith, Mask, DeepMask or their variants.
+type Option func(*maskOptions) error
+
+// maskOptions holds the options of a single masking traversal.
+type maskOptions struct {
+	registry *Registry
+	// tagKeys are the struct tag keys of masker tags in order of precedence, or nil for those of the
+	// registry.
+	tagKeys []string
+	// followPointers applies masking to values behind pointers.
+	followPointers bool
+	// maskSlices applies masking to slice items. Array items are always masked.
+	maskSlices     bool
+	maskMaps       bool
+	maskInterfaces bool
+	maskUnexported bool
+	// maxDepth limits the nesting depth of traversed values, or is 0 for no limit.
+	maxDepth int
+	// errs collects the errors of fields that are zeroed in place of returning an error, if not nil.
+	errs *[]error
+	// report collects a description of each field reached, if not nil.
+	report *Report
+	// profile selects the "<tag key>.<profile>" variant of field tags, if any.
+	profile string
+	// includes are applied in order of precedence to matching fields in place of their tags.
+	includes []includeRule
+	// excludes are patterns of fields left as is.
+	excludes [][]string
+	// excluded is set while traversing an excluded field for any included fields nested within it.
+	excluded bool
+	// included is the masker tag of an include while traversing the included field for the string
+	// fields nested within it.
+	included string
+	// depth is the nesting depth of the value being traversed.
+	depth int
+}
+
+// defaultMaskOptions returns the options of Mask.
+func defaultMaskOptions() maskOptions {
+	return maskOptions{}
+}
+
+// deepMaskOptions returns the options of DeepMask.
+func deepMaskOptions() maskOptions {
+	opts := defaultMaskOptions()
+	opts.followPointers = true
+	opts.maskSlices = true
+	return opts
+}
+
+// Pointers sets whether masking is applied to values behind pointer fields and items. Mask does not
+// follow pointers by default, while DeepMask does.
+func Pointers(enabled bool) Option {
+	return func(opts *maskOptions) error {
+		opts.followPointers = enabled
+		return nil
+	}
+}
+
+// Slices sets whether masking is applied to slice items. Mask does not mask slice items by default,
+// while DeepMask does. Array items are always masked.
+func Slices(enabled bool) Option {
+	return func(opts *maskOptions) error {
+		opts.maskSlices = enabled
+		return nil
+	}
+}
+
+// Maps sets whether masking is applied to map values, which are not masked by default.
+//
+// Map values other than pointers are masked as copies that replace the original values.
+func Maps(enabled bool) Option {
+	return func(opts *maskOptions) error {
+		opts.maskMaps = enabled
+		return nil
+	}
+}
+
+// Interfaces sets whether masking is applied to values held by interface fields, which are not masked
+// by default.
+//
+// Held values other than pointers are masked as copies that replace the original values.
+func Interfaces(enabled bool) Option {
+	return func(opts *maskOptions) error {
+		opts.maskInterfaces = enabled
+		return nil
+	}
+}
+
+// Unexported sets whether masking is applied to unexported fields, which are not masked by default.
+//
+// The value held by a Secret is never masked, since it is untagged.
+func Unexported(enabled bool) Option {
+	return func(opts *maskOptions) error {
+		opts.maskUnexported = enabled
+		return nil
+	}
+}
+
+// MaxDepth limits the nesting depth of traversed structs, slices, arrays and maps to depth, where the
+// fields of the masked value are at depth 1. Masking returns an error for values nested more deeply,
+// such as within a cyclic graph of pointers. A depth of 0 sets no limit, which is the default.
+func MaxDepth(depth int) Option {
+	return func(opts *maskOptions) error {
+		if depth < 0 {
+			return fmt.Errorf("mask: invalid max depth %d", depth)
+		}
+		opts.maxDepth = depth
+		return nil
+	}
+}
+
+// TagKeys sets the struct tag keys of masker tags for this call in place of those of the registry.
+//
+// See SetTagKeys for details.
+func TagKeys(keys ...string) Option {
+	return func(opts *maskOptions) error {
+		if err := validateTagKeys(keys); err != nil {
+			return err
+		}
+		opts.tagKeys = keys
+		return nil
+	}
+}
+
+// FailClosed sets whether a field that cannot be masked, such as due to a masker error, is set to its
+// zero value in place of stopping masking with an error. The errors of all such fields are joined and
+// returned once masking completes. Errors due to a done context always stop masking.
+func FailClosed(enabled bool) Option {
+	return func(opts *maskOptions) error {
+		if enabled {
+			opts.errs = new([]error)
+		} else {
+			opts.errs = nil
+		}
+		return nil
+	}
+}
+
+// WithRegistry masks using the maskers and policies of r in place of the registry the call was made
+// on, such as DefaultRegistry for the package-level functions.
+func WithRegistry(r *Registry) Option {
+	return func(opts *maskOptions) error {
+		if r == nil {
+			return fmt.Errorf("mask: nil registry")
+		}
+		opts.registry = r
+		return nil
+	}
+}
+
+// Profile selects the profile of field tags, as by MaskFor.
+func Profile(profile string) Option {
+	return func(opts *maskOptions) error {
+		opts.profile = profile
+		return validateProfile(profile)
+	}
+}
+
+type includeRule struct {
+	pattern []string
+	maskTag string
+}
+
+// Include applies masking with maskTag to fields matching the field path pattern, in place of their
+// tags, field rules and policies.
+//
+// Field paths are made up of field names separated by dots, relative to the masked value, such as
+// "Customer.Address" of an Order. Slice and array indexes are not part of the path, so "Items.Price"
+// matches the Price field of every item. Within a pattern, each field name may be a glob as accepted
+// by path.Match, such as "*" or "*Id", and "**" matches any number of field names:
+//
+//	masking.Mask(&order, masking.Include("Customer.Address", "X"), masking.Exclude("**.Id"))
+//
+// Include takes precedence over Exclude, and earlier includes take precedence over later ones. Only
+// fields reached by the traversal are matched, so pointer fields are matched only by DeepMask.
+//
+// Including a field that holds structs, such as a struct or a slice of structs, masks every string
+// field nested within it with maskTag in place of their tags. Masking returns an error if pattern
+// cannot match any field of the type of the masked value, such as a pattern starting with the name of
+// that type.
+func Include(pattern, maskTag string) Option {
+	return func(opts *maskOptions) error {
+		segments, err := parseFieldPattern(pattern)
+		if err != nil {
+			return err
+		}
+		if maskTag == "" {
+			return fmt.Errorf("mask: empty mask tag for include \"%s\"", pattern)
+		}
+		opts.includes = append(opts.includes, includeRule{pattern: segments, maskTag: maskTag})
+		return nil
+	}
+}
+
+// Exclude leaves fields matching any of the field path patterns as is, including their nested fields,
+// regardless of their tags. Nested fields selected by Include are still masked, so that
+// Exclude("**") with Include masks only the included fields.
+//
+// See Include for the pattern syntax.
+func Exclude(patterns ...string) Option {
+	return func(opts *maskOptions) error {
+		for _, pattern := range patterns {
+			segments, err := parseFieldPattern(pattern)
+			if err != nil {
+				return err
+			}
+			opts.excludes = append(opts.excludes, segments)
+		}
+		return nil
+	}
+}
+
+// newMaskOptions returns base with opts applied, checking that the maskers given by opts are
+// registered with the registry of the options, or r if none is given.
+func (r *Registry) newMaskOptions(base maskOptions, opts []Option) (maskOptions, error) {
+	base.registry = r
+	for _, opt := range opts {
+		if err := opt(&base); err != nil {
+			return maskOptions{}, err
+		}
+	}
+	if base.tagKeys == nil {
+		base.tagKeys = base.registry.tagKeys
+	}
+	for _, rule := range base.includes {
+		if _, err := base.registry.getFieldMasker(rule.maskTag); err != nil {
+			return maskOptions{}, err
+		}
+	}
+	return base, nil
+}
+
+// validateIncludes checks that the pattern of each include of opts can match a field of values of type
+// t, so that patterns that match nothing, such as those given relative to another type, are not
+// silently ignored.
+func (opts maskOptions) validateIncludes(t reflect.Type) error {
+	if t == nil {
+		return nil
+	}
+	for _, rule := range opts.includes {
+		if !reachesField(t, rule.pattern, map[patternState]bool{}) {
+			pattern := strings.Join(rule.pattern, ".")
+			return fmt.Errorf("mask: include \"%s\" matches no field of %s", pattern, t)
+		}
+	}
+	return nil
+}
+
+// patternState is the remainder of a field path pattern to match within a type.
+type patternState struct {
+	t reflect.Type
+	n int
+}
+
+// reachesField reports whether pattern can match the path of a field nested within values of type t.
+// Fields within maps and interfaces are known only from values, so they are assumed to match.
+func reachesField(t reflect.Type, pattern []string, seen map[patternState]bool) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Map || t.Kind() == reflect.Interface {
+		return true
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	// a type is revisited with the same pattern only through recursion, which matches nothing new
+	state := patternState{t: t, n: len(pattern)}
+	if seen[state] {
+		return false
+	}
+	seen[state] = true
+
+	if pattern[0] == "**" && reachesField(t, pattern[1:], seen) {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if pattern[0] == "**" {
+			if reachesField(field.Type, pattern, seen) {
+				return true
+			}
+			continue
+		}
+		matched, _ := path.Match(pattern[0], field.Name)
+		if matched && reachesField(field.Type, pattern[1:], seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// failClosed returns err unless opts fail closed, in which case val is set to its zero value, err is
+// collected and nil is returned. Errors due to a done context are always returned.
+func (opts maskOptions) failClosed(ctx context.Context, val reflect.Value, err error) error {
+	if opts.errs == nil || ctx.Err() != nil {
+		return err
+	}
+	if val.IsValid() && val.CanSet() {
+		val.Set(reflect.Zero(val.Type()))
+	}
+	*opts.errs = append(*opts.errs, err)
+	return nil
+}
+
+func parseFieldPattern(pattern string) ([]string, error) {
+	segments := strings.Split(pattern, ".")
+	for _, segment := range segments {
+		if _, err := path.Match(segment, ""); segment == "" || err != nil {
+			return nil, fmt.Errorf("mask: invalid field path pattern \"%s\"", pattern)
+		}
+	}
+	return segments, nil
+}
+
+// selectsFields reports whether opts include or exclude any fields.
+func (opts maskOptions) selectsFields() bool {
+	return len(opts.includes) > 0 || len(opts.excludes) > 0
+}
+
+// selectField returns the masker tag of the first include matching fieldPath, or whether the field is
+// excluded if there is none.
+func (opts maskOptions) selectField(fieldPath string) (maskTag string, excluded bool) {
+	segments := fieldPathSegments(fieldPath)
+	for _, rule := range opts.includes {
+		if matchFieldPattern(rule.pattern, segments) {
+			return rule.maskTag, false
+		}
+	}
+	for _, pattern := range opts.excludes {
+		if matchFieldPattern(pattern, segments) {
+			return "", true
+		}
+	}
+	return "", false
+}
+
+// includesStruct reports whether an include matches the field at fieldPath of type t, which holds
+// structs whose nested string fields are masked in place of the field itself.
+func (opts maskOptions) includesStruct(fieldPath string, t reflect.Type) bool {
+	if len(opts.includes) == 0 || !holdsStruct(t) {
+		return false
+	}
+	maskTag, _ := opts.selectField(fieldPath)
+	return maskTag != ""
+}
+
+// fieldPathSegments splits a field path built by the walker, such as "Items[2].Price", into field
+// names without indexes.
+func fieldPathSegments(fieldPath string) []string {
+	segments := strings.Split(fieldPath, ".")
+	for i, segment := range segments {
+		if index := strings.IndexByte(segment, '['); index >= 0 {
+			segments[i] = segment[:index]
+		}
+	}
+	return segments
+}
+
+func matchFieldPattern(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchFieldPattern(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	matched, _ := path.Match(pattern[0], segments[0])
+	return matched && matchFieldPattern(pattern[1:], segments[1:])
+}
@@ -0,0 +1,499 @@
+package masking_test
+
+import (
+	"testing"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Mask_WithInclude_MasksUntaggedFields(t *testing.T) {
+	// arrange
+	type Address struct {
+		Id     string
+		Street string
+		City   string
+	}
+	type Customer struct {
+		Id      string `mask:"X"`
+		Name    string
+		Address Address
+	}
+	type Order struct {
+		Id       string
+		Customer Customer
+	}
+	order := Order{
+		Id: "o-1",
+		Customer: Customer{
+			Id:      "c-1",
+			Name:    "Jane",
+			Address: Address{Id: "a-1", Street: "1 Main St", City: "Springfield"},
+		},
+	}
+
+	// act
+	err := masking.Mask(&order, masking.Include("Customer.Address.*", "*"), masking.Include("Customer.Name", "x"))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, Address{Id: "***", Street: "*********", City: "***********"}, order.Customer.Address)
+	assert.Equal(t, "xxxx", order.Customer.Name)
+	assert.Equal(t, "XXX", order.Customer.Id)
+}
+
+func Test_DeepMask_WithIncludedStructs_MasksNestedStringFields(t *testing.T) {
+	// arrange
+	type Geo struct {
+		Lat string
+		Lng string
+	}
+	type Address struct {
+		Street string `mask:"X"`
+		Zip    int
+		Geo    *Geo
+	}
+	type Item struct {
+		Sku  string
+		Note string
+	}
+	type Order struct {
+		Id      string
+		Address Address
+		Items   []Item
+	}
+	order := Order{
+		Id:      "o-1",
+		Address: Address{Street: "1 Main St", Zip: 12345, Geo: &Geo{Lat: "40.7", Lng: "-74.0"}},
+		Items:   []Item{{Sku: "pen", Note: "gift"}},
+	}
+
+	// act
+	err := masking.DeepMask(&order, masking.Include("Address", "*"), masking.Include("Items", "x"))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, Order{
+		Id:      "o-1",
+		Address: Address{Street: "*********", Zip: 12345, Geo: &Geo{Lat: "****", Lng: "*****"}},
+		Items:   []Item{{Sku: "xxx", Note: "xxxx"}},
+	}, order)
+}
+
+func Test_Mask_WithIncludeMatchingNoField_ReturnsErrorWithoutMasking(t *testing.T) {
+	// arrange
+	type Customer struct {
+		Name    string `mask:"x"`
+		Address string
+	}
+	type Order struct {
+		Customer Customer
+		Meta     map[string]interface{}
+	}
+	testCases := map[string]struct {
+		pattern     string
+		expectedErr string
+	}{
+		"type name prefix": {
+			pattern:     "Order.Customer.Address",
+			expectedErr: `mask: include "Order.Customer.Address" matches no field of *masking_test.Order`,
+		},
+		"misspelled field": {
+			pattern:     "Customer.Adress",
+			expectedErr: `mask: include "Customer.Adress" matches no field of *masking_test.Order`,
+		},
+		"field within map": {
+			pattern: "Meta.Address",
+		},
+		"recursive glob": {
+			pattern: "**.Address",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			order := Order{Customer: Customer{Name: "Jane", Address: "1 Main St"}}
+
+			// act
+			err := masking.Mask(&order, masking.Include(tc.pattern, "*"))
+
+			// assert
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, "xxxx", order.Customer.Name)
+				return
+			}
+			assert.EqualError(t, err, tc.expectedErr)
+			assert.Equal(t, Customer{Name: "Jane", Address: "1 Main St"}, order.Customer)
+		})
+	}
+}
+
+func Test_Mask_WithIncludeOfUnsupportedField_ReturnsErrorWithFieldPath(t *testing.T) {
+	// arrange
+	type Customer struct {
+		Tier int
+	}
+	type Order struct {
+		Customer Customer
+	}
+	order := Order{Customer: Customer{Tier: 2}}
+
+	// act
+	err := masking.Mask(&order, masking.Include("Customer.Tier", "X"))
+
+	// assert
+	assert.EqualError(t, err, "mask: Customer.Tier: X: mask func only supports string types")
+}
+
+func Test_DeepMask_WithExclude_LeavesMatchingFieldsAsIs(t *testing.T) {
+	// arrange
+	type Item struct {
+		Id    string `mask:"X"`
+		Price string `mask:"X"`
+		Name  string `mask:"X"`
+	}
+	type Customer struct {
+		Id   string `mask:"X"`
+		Name string `mask:"X"`
+	}
+	type Order struct {
+		Customer Customer
+		Items    []Item
+	}
+	order := Order{
+		Customer: Customer{Id: "c-1", Name: "Jane"},
+		Items:    []Item{{Id: "i-1", Price: "9.99", Name: "Pen"}},
+	}
+
+	// act
+	err := masking.DeepMask(&order, masking.Exclude("**.Id", "Items.Price"))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, Customer{Id: "c-1", Name: "XXXX"}, order.Customer)
+	assert.Equal(t, Item{Id: "i-1", Price: "9.99", Name: "XXX"}, order.Items[0])
+}
+
+func Test_DeepMask_WithExcludeAllAndInclude_MasksOnlyIncludedFields(t *testing.T) {
+	// arrange
+	type Item struct {
+		Id    string
+		Price string `mask:"X"`
+	}
+	type Customer struct {
+		Id string `mask:"X"`
+	}
+	type Order struct {
+		Customer Customer
+		Items    []Item
+	}
+	order := Order{
+		Customer: Customer{Id: "c-1"},
+		Items:    []Item{{Id: "i-1", Price: "9.99"}},
+	}
+
+	// act
+	err := masking.DeepMask(&order, masking.Exclude("**"), masking.Include("Items.Id", "X"))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "c-1", order.Customer.Id)
+	assert.Equal(t, Item{Id: "XXX", Price: "9.99"}, order.Items[0])
+}
+
+func Test_Mask_WithSingleSegmentGlob_MatchesOneLevel(t *testing.T) {
+	// arrange
+	type Address struct {
+		Id string
+	}
+	type Customer struct {
+		Id      string
+		Address Address
+	}
+	type Order struct {
+		Id       string
+		Customer Customer
+	}
+	order := Order{Id: "o-1", Customer: Customer{Id: "c-1", Address: Address{Id: "a-1"}}}
+
+	// act
+	err := masking.Mask(&order, masking.Include("*.Id", "-"))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, Order{Id: "o-1", Customer: Customer{Id: "---", Address: Address{Id: "a-1"}}}, order)
+}
+
+func Test_Mask_WithInvalidOptions_ReturnsErrorWithoutMasking(t *testing.T) {
+	// arrange
+	type Customer struct {
+		Id   string `mask:"X"`
+		Name string
+	}
+	type Order struct {
+		Customer Customer
+	}
+	testCases := map[string]masking.Option{
+		"empty segment":       masking.Include("Customer..Name", "X"),
+		"invalid glob":        masking.Exclude("Customer.[Name"),
+		"empty mask tag":      masking.Include("Customer.Name", ""),
+		"unrecognized masker": masking.Include("Customer.Name", "nope"),
+	}
+
+	for name, opt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			order := Order{Customer: Customer{Id: "c-1", Name: "Jane"}}
+
+			// act
+			err := masking.Mask(&order, opt)
+
+			// assert
+			assert.Error(t, err)
+			assert.Equal(t, "c-1", order.Customer.Id)
+		})
+	}
+}
+
+func Test_MaskWith_WithoutOptions_MasksAsMask(t *testing.T) {
+	// arrange
+	type Card struct {
+		Number string `mask:"X,showback=4"`
+	}
+	type Wallet struct {
+		Default Card
+		Primary *Card
+		Cards   []Card
+	}
+	wallet := Wallet{
+		Default: Card{Number: "4000000000000002"},
+		Primary: &Card{Number: "4111111111111111"},
+		Cards:   []Card{{Number: "5500000000000004"}},
+	}
+
+	// act
+	err := masking.MaskWith(&wallet)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "XXXXXXXXXXXX0002", wallet.Default.Number)
+	assert.Equal(t, "4111111111111111", wallet.Primary.Number)
+	assert.Equal(t, "5500000000000004", wallet.Cards[0].Number)
+}
+
+func Test_MaskWith_WithTraversalOptions_MasksSelectedValues(t *testing.T) {
+	// arrange
+	type Card struct {
+		Number string `mask:"X,showback=4"`
+	}
+	type Wallet struct {
+		Primary  *Card
+		Cards    []Card
+		ByName   map[string]Card
+		ByRef    map[string]*Card
+		Any      interface{}
+		Tags     map[string]string
+		backup   Card
+		internal string `mask:"*"`
+	}
+	wallet := Wallet{
+		Primary:  &Card{Number: "4111111111111111"},
+		Cards:    []Card{{Number: "5500000000000004"}},
+		ByName:   map[string]Card{"work": {Number: "340000000000009"}},
+		ByRef:    map[string]*Card{"home": {Number: "6011000000000004"}},
+		Any:      Card{Number: "3530111333300000"},
+		Tags:     map[string]string{"note": "hello"},
+		backup:   Card{Number: "4000000000000002"},
+		internal: "secret",
+	}
+
+	// act
+	err := masking.MaskWith(&wallet,
+		masking.Pointers(true),
+		masking.Slices(true),
+		masking.Maps(true),
+		masking.Interfaces(true),
+		masking.Unexported(true))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "XXXXXXXXXXXX1111", wallet.Primary.Number)
+	assert.Equal(t, "XXXXXXXXXXXX0004", wallet.Cards[0].Number)
+	assert.Equal(t, "XXXXXXXXXXX0009", wallet.ByName["work"].Number)
+	assert.Equal(t, "XXXXXXXXXXXX0004", wallet.ByRef["home"].Number)
+	assert.Equal(t, Card{Number: "XXXXXXXXXXXX0000"}, wallet.Any)
+	assert.Equal(t, "hello", wallet.Tags["note"])
+	assert.Equal(t, "XXXXXXXXXXXX0002", wallet.backup.Number)
+	assert.Equal(t, "******", wallet.internal)
+}
+
+func Test_DeepMask_WithPointersHeldByInterfaces_MasksPointedToValues(t *testing.T) {
+	// arrange
+	type Card struct {
+		Number string `mask:"X,showback=4"`
+	}
+	type Wallet struct {
+		Any    interface{}
+		ByName map[string]interface{}
+	}
+	wallet := Wallet{
+		Any:    &Card{Number: "4111111111111111"},
+		ByName: map[string]interface{}{"work": &Card{Number: "5500000000000004"}},
+	}
+
+	// act
+	err := masking.DeepMask(&wallet, masking.Maps(true), masking.Interfaces(true))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, &Card{Number: "XXXXXXXXXXXX1111"}, wallet.Any)
+	assert.Equal(t, &Card{Number: "XXXXXXXXXXXX0004"}, wallet.ByName["work"])
+}
+
+func Test_DeepMask_WithOptionsDisablingTraversal_OverridesDefaults(t *testing.T) {
+	// arrange
+	type Card struct {
+		Number string `mask:"X,showback=4"`
+	}
+	type Wallet struct {
+		Primary *Card
+		Cards   []Card
+	}
+	wallet := Wallet{
+		Primary: &Card{Number: "4111111111111111"},
+		Cards:   []Card{{Number: "5500000000000004"}},
+	}
+
+	// act
+	err := masking.DeepMask(&wallet, masking.Pointers(false))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "4111111111111111", wallet.Primary.Number)
+	assert.Equal(t, "XXXXXXXXXXXX0004", wallet.Cards[0].Number)
+}
+
+func Test_MaskWith_WithMaxDepth_ReturnsErrorForDeeperValues(t *testing.T) {
+	// arrange
+	type Node struct {
+		Name string `mask:"X"`
+		Next *Node
+	}
+	node := &Node{Name: "a"}
+	node.Next = node
+
+	// act
+	err := masking.MaskWith(node, masking.Pointers(true), masking.MaxDepth(3))
+
+	// assert
+	assert.EqualError(t, err, "mask: Next.Next.Next: exceeds max depth 3")
+	assert.Equal(t, "X", node.Name)
+}
+
+func Test_MaskWith_WithTagKeys_UsesTagKeys(t *testing.T) {
+	// arrange
+	type Account struct {
+		Number string `mask:"X" redact:"*" redact.support:"*,showback=2"`
+	}
+	account, supportAccount := Account{Number: "1234"}, Account{Number: "1234"}
+
+	// act
+	err := masking.MaskWith(&account, masking.TagKeys("redact"))
+	supportErr := masking.MaskWith(&supportAccount, masking.TagKeys("redact"), masking.Profile("support"))
+
+	// assert
+	assert.NoError(t, err)
+	assert.NoError(t, supportErr)
+	assert.Equal(t, "****", account.Number)
+	assert.Equal(t, "**34", supportAccount.Number)
+}
+
+func Test_MaskWith_WithFailClosed_ZeroesFieldsThatCannotBeMasked(t *testing.T) {
+	// arrange
+	type Item struct {
+		Number string `mask:"X"`
+	}
+	type Account struct {
+		Number  string `mask:"X"`
+		Balance int    `mask:"X"`
+		Token   string `mask:"nope"`
+		Items   []Item
+	}
+	account := Account{Number: "1234", Balance: 100, Token: "abc", Items: []Item{{Number: "1"}}}
+
+	// act
+	err := masking.MaskWith(&account, masking.FailClosed(true))
+
+	// assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "only supports string types")
+	assert.Contains(t, err.Error(), "unrecognized mask func")
+	assert.Equal(t, Account{Number: "XXXX", Items: []Item{{Number: "1"}}}, account)
+}
+
+func Test_MaskWith_WithRegistry_UsesRegistryMaskers(t *testing.T) {
+	// arrange
+	r := masking.NewRegistry()
+	err := masking.RegisterMaskerIn(r, "redacted", func(string) string { return "[redacted]" })
+	assert.NoError(t, err)
+	type Account struct {
+		Number string `mask:"redacted"`
+	}
+	account := Account{Number: "1234"}
+
+	// act
+	defaultErr := masking.MaskWith(&Account{Number: "1234"})
+	err = masking.MaskWith(&account, masking.WithRegistry(r))
+
+	// assert
+	assert.Error(t, defaultErr)
+	assert.NoError(t, err)
+	assert.Equal(t, "[redacted]", account.Number)
+}
+
+func Test_MaskWith_WithInvalidTraversalOptions_ReturnsError(t *testing.T) {
+	// arrange
+	type Card struct {
+		Number string `mask:"X"`
+	}
+	testCases := map[string]masking.Option{
+		"negative max depth": masking.MaxDepth(-1),
+		"empty tag key":      masking.TagKeys(""),
+		"invalid tag key":    masking.TagKeys("mask:"),
+		"nil registry":       masking.WithRegistry(nil),
+		"invalid profile":    masking.Profile("a b"),
+	}
+
+	for name, opt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			card := Card{Number: "1234"}
+
+			// act
+			err := masking.MaskWith(&card, opt)
+
+			// assert
+			assert.Error(t, err)
+			assert.Equal(t, "1234", card.Number)
+		})
+	}
+}
+
+func Test_MaskWith_WithUnexportedAndSecret_LeavesSecretValueAsIs(t *testing.T) {
+	// arrange
+	type User struct {
+		Name string `mask:"X"`
+	}
+	type Account struct {
+		Owner masking.Secret[User]
+		note  string `mask:"*"`
+	}
+	account := Account{Owner: masking.NewSecret(User{Name: "Jane Doe"}, "*"), note: "vip"}
+
+	// act
+	err := masking.MaskWith(&account, masking.Unexported(true))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, User{Name: "Jane Doe"}, account.Owner.Reveal())
+	assert.Equal(t, "***", account.note)
+}
@@ -0,0 +1,173 @@
+package masking
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy maps fields of named struct types to masker tags.
+//
+// Policies allow masking of types whose struct tags cannot be modified, such as third-party or
+// generated types. A Registry consults its policies for any struct field without a mask tag.
+type Policy struct {
+	// fields maps fully qualified type names to field names to masker tags
+	fields map[string]map[string]string
+}
+
+type policyFile struct {
+	Fields map[string]string `yaml:"fields"`
+}
+
+// LoadPolicy reads a policy in YAML or JSON format from r.
+//
+// The policy maps fully qualified field names, made up of the package path, type name and field
+// name, to masker tags:
+//
+//	fields:
+//	  github.com/acme/api.User.Email: "*"
+//	  github.com/acme/api.User.AccountNumber: X,showback=4
+func LoadPolicy(r io.Reader) (*Policy, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var pf policyFile
+	if err := decoder.Decode(&pf); err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("policy: empty policy")
+		}
+		return nil, fmt.Errorf("policy: %w", err)
+	}
+
+	return NewPolicy(pf.Fields)
+}
+
+// NewPolicy returns a Policy mapping fully qualified field names, such as
+// "github.com/acme/api.User.Email", to masker tags.
+func NewPolicy(fields map[string]string) (*Policy, error) {
+	p := &Policy{
+		fields: make(map[string]map[string]string),
+	}
+
+	for fieldPath, maskTag := range fields {
+		sep := strings.LastIndex(fieldPath, ".")
+		if sep <= 0 || sep == len(fieldPath)-1 {
+			return nil, fmt.Errorf("policy: invalid field path: \"%s\"", fieldPath)
+		}
+		if maskTag == "" {
+			return nil, fmt.Errorf("policy: empty mask tag for field: \"%s\"", fieldPath)
+		}
+
+		typeName, fieldName := fieldPath[:sep], fieldPath[sep+1:]
+		if p.fields[typeName] == nil {
+			p.fields[typeName] = make(map[string]string)
+		}
+		p.fields[typeName][fieldName] = maskTag
+	}
+
+	return p, nil
+}
+
+// Validate reports any fields of the policy that do not refer to a field of one of types.
+//
+// Types are given by example values, such as api.User{}, or by their reflect.Type.
+func (p *Policy) Validate(types ...interface{}) error {
+	knownTypes := make(map[string]reflect.Type)
+	for _, v := range types {
+		t, isType := v.(reflect.Type)
+		if !isType {
+			t = reflect.TypeOf(v)
+		}
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if name := qualifiedTypeName(t); name != "" {
+			knownTypes[name] = t
+		}
+	}
+
+	var problems []string
+	for typeName, fields := range p.fields {
+		t, found := knownTypes[typeName]
+		if !found {
+			problems = append(problems, fmt.Sprintf("unknown type \"%s\"", typeName))
+			continue
+		}
+		for fieldName, maskTag := range fields {
+			if t.Kind() != reflect.Struct {
+				problems = append(problems, fmt.Sprintf("type \"%s\" is not a struct", typeName))
+				break
+			}
+			// only fields declared directly on the type are masked by policies, not promoted fields
+			if field, found := t.FieldByName(fieldName); !found || len(field.Index) != 1 {
+				problems = append(problems, fmt.Sprintf("unknown field \"%s.%s\"", typeName, fieldName))
+				continue
+			}
+			_, conds, err := parseFieldMaskTag(maskTag)
+			if err == nil {
+				err = conds.validate(t)
+			}
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("field \"%s.%s\": %v", typeName, fieldName, err))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("policy: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// maskTag returns the masker tag of the policy for the named field of t, if any.
+func (p *Policy) maskTag(t reflect.Type, fieldName string) string {
+	return p.fields[qualifiedTypeName(t)][fieldName]
+}
+
+// UsePolicy adds p to the policies consulted by DefaultRegistry.
+func UsePolicy(p *Policy) error {
+	return DefaultRegistry.UsePolicy(p)
+}
+
+// UsePolicy adds p to the policies consulted by r for fields without a mask tag.
+//
+// Policies added later take precedence over those added earlier. An error is returned if p refers to
+// a masker that is not registered with r.
+func (r *Registry) UsePolicy(p *Policy) error {
+	for _, fields := range p.fields {
+		for _, maskTag := range fields {
+			if _, err := r.getFieldMasker(maskTag); err != nil {
+				return fmt.Errorf("policy: %w", err)
+			}
+		}
+	}
+
+	r.policies = append(r.policies, p)
+	return nil
+}
+
+// policyMaskTag returns the masker tag for the named field of t from the policies of r, if any.
+func (r *Registry) policyMaskTag(t reflect.Type, fieldName string) string {
+	for i := len(r.policies) - 1; i >= 0; i-- {
+		if maskTag := r.policies[i].maskTag(t, fieldName); maskTag != "" {
+			return maskTag
+		}
+	}
+	return ""
+}
+
+// qualifiedTypeName returns the package path and name of t, or an empty string if t is not named.
+func qualifiedTypeName(t reflect.Type) string {
+	if t == nil || t.Name() == "" {
+		return ""
+	}
+	if t.PkgPath() == "" {
+		return t.Name()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
@@ -0,0 +1,201 @@
+package masking_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LoadPolicy_WithYAML_MasksUntaggedFields(t *testing.T) {
+	// arrange
+	type User struct {
+		Name          string
+		Email         string
+		AccountNumber string
+		Password      string `mask:"*"`
+	}
+	policy, err := masking.LoadPolicy(strings.NewReader(`
+fields:
+  github.com/dgravesa/go-mask/masking_test.User.Email: x
+  github.com/dgravesa/go-mask/masking_test.User.AccountNumber: X,showback=4
+`))
+	assert.NoError(t, err)
+	r := masking.NewRegistry()
+	err = r.UsePolicy(policy)
+	assert.NoError(t, err)
+	user := User{
+		Name:          "Jane",
+		Email:         "jane@example.com",
+		AccountNumber: "123456789",
+		Password:      "hunter2",
+	}
+
+	// act
+	err = r.Mask(&user)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, User{
+		Name:          "Jane",
+		Email:         "xxxxxxxxxxxxxxxx",
+		AccountNumber: "XXXXX6789",
+		Password:      "*******",
+	}, user)
+}
+
+func Test_LoadPolicy_WithJSON_MasksUntaggedFields(t *testing.T) {
+	// arrange
+	type User struct {
+		Name          string
+		Email         string
+		AccountNumber string
+		Password      string `mask:"*"`
+	}
+	policy, err := masking.LoadPolicy(strings.NewReader(`{
+		"fields": {"github.com/dgravesa/go-mask/masking_test.User.Email": "*"}
+	}`))
+	assert.NoError(t, err)
+	r := masking.NewRegistry()
+	err = r.UsePolicy(policy)
+	assert.NoError(t, err)
+	user := User{
+		Email: "jane@example.com",
+	}
+
+	// act
+	err = r.Mask(&user)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "****************", user.Email)
+}
+
+func Test_LoadPolicy_WithTaggedField_PrefersTag(t *testing.T) {
+	// arrange
+	type User struct {
+		Name          string
+		Email         string
+		AccountNumber string
+		Password      string `mask:"*"`
+	}
+	policy, err := masking.NewPolicy(map[string]string{
+		"github.com/dgravesa/go-mask/masking_test.User.Password": "X",
+	})
+	assert.NoError(t, err)
+	r := masking.NewRegistry()
+	err = r.UsePolicy(policy)
+	assert.NoError(t, err)
+	user := User{
+		Password: "hunter2",
+	}
+
+	// act
+	err = r.Mask(&user)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "*******", user.Password)
+}
+
+func Test_LoadPolicy_WithInvalidFieldPath_ReturnsError(t *testing.T) {
+	// act
+	_, err := masking.LoadPolicy(strings.NewReader(`
+fields:
+  Email: "*"
+`))
+
+	// assert
+	assert.Error(t, err)
+}
+
+func Test_LoadPolicy_WithUnknownKey_ReturnsError(t *testing.T) {
+	// act
+	_, err := masking.LoadPolicy(strings.NewReader(`
+feilds:
+  github.com/dgravesa/go-mask/masking_test.User.Email: "*"
+`))
+
+	// assert
+	assert.Error(t, err)
+}
+
+func Test_Registry_UsePolicy_WithUnrecognizedMaskFunc_ReturnsError(t *testing.T) {
+	// arrange
+	policy, err := masking.NewPolicy(map[string]string{
+		"github.com/dgravesa/go-mask/masking_test.User.Email": "idk",
+	})
+	assert.NoError(t, err)
+
+	// act
+	err = masking.NewRegistry().UsePolicy(policy)
+
+	// assert
+	assert.Error(t, err)
+}
+
+func Test_Policy_Validate_ReportsUnknownTypesAndFields(t *testing.T) {
+	// arrange
+	type User struct {
+		Name          string
+		Email         string
+		AccountNumber string
+		Password      string `mask:"*"`
+	}
+	policy, err := masking.NewPolicy(map[string]string{
+		"github.com/dgravesa/go-mask/masking_test.User.Email":    "*",
+		"github.com/dgravesa/go-mask/masking_test.User.Address":  "*",
+		"github.com/dgravesa/go-mask/masking_test.Unknown.Email": "*",
+	})
+	assert.NoError(t, err)
+
+	// act
+	err = policy.Validate(User{})
+
+	// assert
+	assert.EqualError(t, err, `policy: `+
+		`unknown field "github.com/dgravesa/go-mask/masking_test.User.Address"; `+
+		`unknown type "github.com/dgravesa/go-mask/masking_test.Unknown"`)
+}
+
+func Test_Policy_Validate_WithKnownFields_ReturnsNoError(t *testing.T) {
+	// arrange
+	type User struct {
+		Name          string
+		Email         string
+		AccountNumber string
+		Password      string `mask:"*"`
+	}
+	policy, err := masking.NewPolicy(map[string]string{
+		"github.com/dgravesa/go-mask/masking_test.User.Email": "*",
+	})
+	assert.NoError(t, err)
+
+	// act
+	err = policy.Validate(&User{})
+
+	// assert
+	assert.NoError(t, err)
+}
+
+func Test_Policy_Validate_WithPromotedField_ReturnsError(t *testing.T) {
+	// arrange
+	type Base struct {
+		Email string
+	}
+	type Account struct {
+		Base
+		Name string
+	}
+	policy, err := masking.NewPolicy(map[string]string{
+		"github.com/dgravesa/go-mask/masking_test.Account.Email": "*",
+	})
+	assert.NoError(t, err)
+
+	// act
+	err = policy.Validate(Account{})
+
+	// assert
+	assert.EqualError(t, err, `policy: unknown field "github.com/dgravesa/go-mask/masking_test.Account.Email"`)
+}
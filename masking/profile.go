@@ -0,0 +1,57 @@
+package masking
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MaskFor applies masking to public fields of v as seen by the given profile, such as a role or
+// audience of the masked value.
+//
+// A field may specify the masker for a profile with a "mask.<profile>" tag, which takes precedence
+// over its "mask" tag, field rules and policies. Profile tags of other tag keys, as set by SetTagKeys
+// or TagKeys, are named in the same way, such as "pii.support". An empty profile tag leaves the field
+// unmasked for the profile. Fields without a tag for the profile are masked as by Mask.
+//
+//	type Card struct {
+//		Number string `mask:"X" mask.support:"X,showback=4" mask.audit:""`
+//	}
+//
+// Note that "-" is the dash masker, as in any other tag, rather than a way to skip masking.
+//
+// ctx is passed to context-aware maskers, as by MaskContext. An empty profile is the same as
+// MaskContext.
+func MaskFor(ctx context.Context, profile string, v interface{}, opts ...Option) error {
+	return DefaultRegistry.MaskFor(ctx, profile, v, opts...)
+}
+
+// DeepMaskFor applies masking to all public fields of v, including pointers and slices, as seen by
+// the given profile.
+//
+// See MaskFor for details on profiles.
+func DeepMaskFor(ctx context.Context, profile string, v interface{}, opts ...Option) error {
+	return DefaultRegistry.DeepMaskFor(ctx, profile, v, opts...)
+}
+
+// MaskFor applies masking to public fields of v as seen by the given profile using the maskers and
+// policies of r.
+//
+// See the package-level MaskFor for details.
+func (r *Registry) MaskFor(ctx context.Context, profile string, v interface{}, opts ...Option) error {
+	return r.maskWith(ctx, v, defaultMaskOptions(), append([]Option{Profile(profile)}, opts...))
+}
+
+// DeepMaskFor applies masking to all public fields of v, including pointers and slices, as seen by
+// the given profile using the maskers and policies of r.
+func (r *Registry) DeepMaskFor(ctx context.Context, profile string, v interface{}, opts ...Option) error {
+	return r.maskWith(ctx, v, deepMaskOptions(), append([]Option{Profile(profile)}, opts...))
+}
+
+// validateProfile returns an error if profile cannot be used as part of a struct tag key.
+func validateProfile(profile string) error {
+	if strings.ContainsFunc(profile, invalidTagKeyRune) {
+		return fmt.Errorf("mask: invalid profile name \"%s\"", profile)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package masking_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MaskFor_WithProfiles_UsesProfileTags(t *testing.T) {
+	// arrange
+	type Card struct {
+		Holder string `mask:"x"`
+		Number string `mask:"X" mask.support:"X,showback=4" mask.audit:""`
+		CVV    string `mask:"*" mask.audit:"*"`
+	}
+	testCases := map[string]Card{
+		"":         {Holder: "xxxx", Number: "XXXXXXXXXXXXXXXX", CVV: "***"},
+		"support":  {Holder: "xxxx", Number: "XXXXXXXXXXXX1111", CVV: "***"},
+		"audit":    {Holder: "xxxx", Number: "4111111111111111", CVV: "***"},
+		"external": {Holder: "xxxx", Number: "XXXXXXXXXXXXXXXX", CVV: "***"},
+	}
+
+	for profile, expected := range testCases {
+		t.Run(profile, func(t *testing.T) {
+			card := Card{Holder: "Jane", Number: "4111111111111111", CVV: "123"}
+
+			// act
+			err := masking.MaskFor(context.Background(), profile, &card)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, expected, card)
+		})
+	}
+}
+
+func Test_MaskFor_WithEmptyProfileTagOnStruct_LeavesNestedFieldsUnmasked(t *testing.T) {
+	// arrange
+	type Card struct {
+		Holder string `mask:"x"`
+		Number string `mask:"X" mask.support:"X,showback=4" mask.audit:""`
+		CVV    string `mask:"*" mask.audit:"*"`
+	}
+	type Account struct {
+		Card  Card   `mask.audit:""`
+		Email string `mask:"X"`
+	}
+	account := Account{Card: Card{Holder: "Jane", Number: "4111", CVV: "123"}, Email: "j@x.io"}
+
+	// act
+	err := masking.MaskFor(context.Background(), "audit", &account)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, Card{Holder: "Jane", Number: "4111", CVV: "123"}, account.Card)
+	assert.Equal(t, "XXXXXX", account.Email)
+}
+
+func Test_DeepMaskFor_WithPointers_UsesProfileTags(t *testing.T) {
+	// arrange
+	type Card struct {
+		Holder string `mask:"x"`
+		Number string `mask:"X" mask.support:"X,showback=4" mask.audit:""`
+		CVV    string `mask:"*" mask.audit:"*"`
+	}
+	type Wallet struct {
+		Cards []*Card
+	}
+	wallet := Wallet{Cards: []*Card{{Holder: "Jane", Number: "4111111111111111", CVV: "123"}}}
+
+	// act
+	err := masking.DeepMaskFor(context.Background(), "support", &wallet)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "XXXXXXXXXXXX1111", wallet.Cards[0].Number)
+	assert.Equal(t, "***", wallet.Cards[0].CVV)
+}
+
+func Test_MaskFor_WithCanceledContext_ReturnsErrorWithoutMasking(t *testing.T) {
+	// arrange
+	type Card struct {
+		Holder string `mask:"x"`
+		Number string `mask:"X" mask.support:"X,showback=4" mask.audit:""`
+		CVV    string `mask:"*" mask.audit:"*"`
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	card := Card{Holder: "Jane"}
+
+	// act
+	err := masking.MaskFor(ctx, "support", &card)
+
+	// assert
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "Jane", card.Holder)
+}
+
+func Test_MaskFor_WithInvalidProfile_ReturnsError(t *testing.T) {
+	// arrange
+	type Card struct {
+		Holder string `mask:"x"`
+		Number string `mask:"X" mask.support:"X,showback=4" mask.audit:""`
+		CVV    string `mask:"*" mask.audit:"*"`
+	}
+	card := Card{Holder: "Jane"}
+
+	// act
+	err := masking.MaskFor(context.Background(), "sup port", &card)
+
+	// assert
+	assert.Error(t, err)
+	assert.Equal(t, "Jane", card.Holder)
+}
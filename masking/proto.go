@@ -0,0 +1,172 @@
+package masking
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ProtoRules selects the masking to apply to fields of protobuf messages.
+//
+// Rules apply to string fields, and to the elements of repeated string fields and the values of maps
+// with string values. When several rules select the same field, Paths take precedence, followed by
+// Fields, Names and then Option.
+type ProtoRules struct {
+	// Paths maps field paths relative to the masked message, such as "user.address.street", to masker
+	// tags. Path elements are proto field names; repeated and map fields are traversed without indices.
+	Paths map[string]string
+	// Fields maps fully qualified field names, such as "acme.v1.User.email", to masker tags.
+	Fields map[string]string
+	// Names selects masker tags by proto field name, wherever the field appears.
+	Names KeyPolicy
+	// Option is a custom field option of string type holding a masker tag, such as the extension
+	// declared by `extend google.protobuf.FieldOptions { string mask = 50000; }`.
+	Option protoreflect.ExtensionType
+	// ClearUnmaskable clears fields that cannot be masked, either because they are not of string type or
+	// because their masker returns an error, instead of returning an error.
+	ClearUnmaskable bool
+}
+
+// MaskProto applies masking to fields of msg, and of messages nested within it, selected by rules.
+//
+// Unlike Mask, MaskProto walks the message using protobuf reflection, so it applies to generated
+// message types whose fields cannot carry mask tags.
+func MaskProto(msg proto.Message, rules ProtoRules) error {
+	return DefaultRegistry.MaskProto(msg, rules)
+}
+
+// MaskProto applies masking to fields of msg selected by rules using the maskers of r.
+func (r *Registry) MaskProto(msg proto.Message, rules ProtoRules) error {
+	if msg == nil || !msg.ProtoReflect().IsValid() {
+		return fmt.Errorf("mask: expected non-nil message")
+	}
+	if err := rules.Validate(); err != nil {
+		return err
+	}
+
+	return r.maskProtoMessage(msg.ProtoReflect(), rules, "")
+}
+
+func (r *Registry) maskProtoMessage(msg protoreflect.Message, rules ProtoRules, parentPath string) error {
+	var err error
+	msg.Range(func(fd protoreflect.FieldDescriptor, val protoreflect.Value) bool {
+		fieldPath := joinKeyPath(parentPath, string(fd.Name()))
+
+		if maskTag := rules.maskTag(fd, fieldPath); maskTag != "" {
+			err = r.maskProtoField(msg, fd, val, maskTag)
+			if err != nil && rules.ClearUnmaskable {
+				msg.Clear(fd)
+				err = nil
+			}
+			if err != nil {
+				err = fmt.Errorf("%s: %w", fieldPath, err)
+			}
+			return err == nil
+		}
+
+		// mask nested messages recursively
+		switch {
+		case fd.IsList() && fd.Kind() == protoreflect.MessageKind:
+			list := val.List()
+			for i := 0; i < list.Len() && err == nil; i++ {
+				err = r.maskProtoMessage(list.Get(i).Message(), rules, fieldPath)
+			}
+		case fd.IsMap() && fd.MapValue().Kind() == protoreflect.MessageKind:
+			val.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+				err = r.maskProtoMessage(v.Message(), rules, fieldPath)
+				return err == nil
+			})
+		case !fd.IsList() && !fd.IsMap() && fd.Kind() == protoreflect.MessageKind:
+			err = r.maskProtoMessage(val.Message(), rules, fieldPath)
+		}
+		return err == nil
+	})
+	return err
+}
+
+// maskProtoField applies the masker given by maskTag to a string field, or to the elements or values of
+// a repeated or map field of strings.
+func (r *Registry) maskProtoField(msg protoreflect.Message, fd protoreflect.FieldDescriptor,
+	val protoreflect.Value, maskTag string) error {
+	maskString := func(s string) (protoreflect.Value, error) {
+		err := r.MaskValue(&s, maskTag)
+		return protoreflect.ValueOfString(s), err
+	}
+
+	switch {
+	case fd.IsList() && fd.Kind() == protoreflect.StringKind:
+		list := val.List()
+		for i := 0; i < list.Len(); i++ {
+			masked, err := maskString(list.Get(i).String())
+			if err != nil {
+				return err
+			}
+			list.Set(i, masked)
+		}
+
+	case fd.IsMap() && fd.MapValue().Kind() == protoreflect.StringKind:
+		m := val.Map()
+		var err error
+		m.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			var masked protoreflect.Value
+			masked, err = maskString(v.String())
+			if err == nil {
+				m.Set(k, masked)
+			}
+			return err == nil
+		})
+		return err
+
+	case !fd.IsList() && !fd.IsMap() && fd.Kind() == protoreflect.StringKind:
+		masked, err := maskString(val.String())
+		if err != nil {
+			return err
+		}
+		msg.Set(fd, masked)
+
+	default:
+		return fmt.Errorf("%s: mask func only supports string fields", maskTag)
+	}
+
+	return nil
+}
+
+// Validate checks that the field names and option of rules are well formed.
+func (rules ProtoRules) Validate() error {
+	if rules.Option != nil && rules.Option.TypeDescriptor().Kind() != protoreflect.StringKind {
+		return fmt.Errorf("mask: proto option must be a string field")
+	}
+	for name := range rules.Fields {
+		if !protoreflect.FullName(name).IsValid() {
+			return fmt.Errorf("mask: invalid proto field name: \"%s\"", name)
+		}
+	}
+	for fieldPath := range rules.Paths {
+		for _, name := range strings.Split(fieldPath, ".") {
+			if !protoreflect.Name(name).IsValid() {
+				return fmt.Errorf("mask: invalid proto field path: \"%s\"", fieldPath)
+			}
+		}
+	}
+	return nil
+}
+
+// maskTag returns the masker tag selected by rules for the field fd at fieldPath, if any.
+func (rules ProtoRules) maskTag(fd protoreflect.FieldDescriptor, fieldPath string) string {
+	if maskTag, found := rules.Paths[fieldPath]; found {
+		return maskTag
+	}
+	if maskTag, found := rules.Fields[string(fd.FullName())]; found {
+		return maskTag
+	}
+	if maskTag, found := rules.Names.Match(string(fd.Name())); found {
+		return maskTag
+	}
+	if rules.Option == nil || fd.Options() == nil || !proto.HasExtension(fd.Options(), rules.Option) {
+		return ""
+	}
+	maskTag, _ := proto.GetExtension(fd.Options(), rules.Option).(string)
+	return maskTag
+}
@@ -0,0 +1,199 @@
+package masking_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/sourcecontextpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/typepb"
+
+	"github.com/dgravesa/go-mask/masking"
+)
+
+func Test_MaskProto_WithFieldNames_MasksSelectedFields(t *testing.T) {
+	// arrange
+	api := &apipb.Api{
+		Name:          "acme.v1.Users",
+		Version:       "v1.2.3",
+		SourceContext: &sourcecontextpb.SourceContext{FileName: "acme/v1/users.proto"},
+	}
+	rules := masking.ProtoRules{
+		Fields: map[string]string{
+			"google.protobuf.Api.version":             "X",
+			"google.protobuf.SourceContext.file_name": "X,showback=6",
+		},
+	}
+
+	// act
+	err := masking.MaskProto(api, rules)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "acme.v1.Users", api.Name)
+	assert.Equal(t, "XXXXXX", api.Version)
+	assert.Equal(t, "XXXXXXXXXXXXX.proto", api.SourceContext.FileName)
+}
+
+func Test_MaskProto_WithPaths_MasksFieldsInRepeatedMessages(t *testing.T) {
+	// arrange
+	api := &apipb.Api{
+		Name: "acme.v1.Users",
+		Methods: []*apipb.Method{
+			{Name: "Get", RequestTypeUrl: "type.googleapis.com/acme.v1.GetRequest"},
+			{Name: "List", RequestTypeUrl: "type.googleapis.com/acme.v1.ListRequest"},
+		},
+	}
+	rules := masking.ProtoRules{
+		Paths: map[string]string{
+			"methods.name": "*",
+		},
+	}
+
+	// act
+	err := masking.MaskProto(api, rules)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "acme.v1.Users", api.Name)
+	assert.Equal(t, "***", api.Methods[0].Name)
+	assert.Equal(t, "****", api.Methods[1].Name)
+	assert.Equal(t, "type.googleapis.com/acme.v1.GetRequest", api.Methods[0].RequestTypeUrl)
+}
+
+func Test_MaskProto_WithNames_MasksMatchingFieldsAnywhere(t *testing.T) {
+	// arrange
+	api := &apipb.Api{
+		Name: "acme.v1.Users",
+		Methods: []*apipb.Method{
+			{Name: "Get", RequestTypeUrl: "acme.v1.GetRequest", ResponseTypeUrl: "acme.v1.User"},
+		},
+	}
+	rules := masking.ProtoRules{
+		Names: masking.KeyPolicy{
+			Rules: []masking.KeyRule{{Key: "*_type_url", Mask: "x"}},
+		},
+	}
+
+	// act
+	err := masking.MaskProto(api, rules)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Get", api.Methods[0].Name)
+	assert.Equal(t, "xxxxxxxxxxxxxxxxxx", api.Methods[0].RequestTypeUrl)
+	assert.Equal(t, "xxxxxxxxxxxx", api.Methods[0].ResponseTypeUrl)
+}
+
+func Test_MaskProto_WithMapsAndOneofs_MasksValues(t *testing.T) {
+	// arrange
+	s, err := structpb.NewStruct(map[string]interface{}{
+		"password": "hunter2",
+		"attempts": 3.0,
+		"nested":   map[string]interface{}{"token": "abc"},
+	})
+	assert.NoError(t, err)
+	rules := masking.ProtoRules{
+		Fields: map[string]string{
+			"google.protobuf.Value.string_value": "*",
+		},
+	}
+
+	// act
+	err = masking.MaskProto(s, rules)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"password": "*******",
+		"attempts": 3.0,
+		"nested":   map[string]interface{}{"token": "***"},
+	}, s.AsMap())
+}
+
+func Test_MaskProto_WithRepeatedStringField_MasksEachElement(t *testing.T) {
+	// arrange
+	typ := &typepb.Type{
+		Name:   "acme.v1.User",
+		Oneofs: []string{"contact", "id"},
+	}
+	rules := masking.ProtoRules{
+		Paths: map[string]string{"oneofs": "X"},
+	}
+
+	// act
+	err := masking.MaskProto(typ, rules)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"XXXXXXX", "XX"}, typ.Oneofs)
+}
+
+func Test_MaskProto_WithNonStringField_ReturnsError(t *testing.T) {
+	// arrange
+	typ := &typepb.Type{
+		Name:   "acme.v1.User",
+		Syntax: typepb.Syntax_SYNTAX_PROTO3,
+	}
+	rules := masking.ProtoRules{
+		Paths: map[string]string{"syntax": "X"},
+	}
+
+	// act
+	err := masking.MaskProto(typ, rules)
+
+	// assert
+	assert.Error(t, err)
+	assert.Equal(t, typepb.Syntax_SYNTAX_PROTO3, typ.Syntax)
+}
+
+func Test_MaskProto_WithClearUnmaskable_ClearsField(t *testing.T) {
+	// arrange
+	typ := &typepb.Type{
+		Name:   "acme.v1.User",
+		Syntax: typepb.Syntax_SYNTAX_PROTO3,
+	}
+	rules := masking.ProtoRules{
+		Paths:           map[string]string{"syntax": "X", "name": "unregistered"},
+		ClearUnmaskable: true,
+	}
+
+	// act
+	err := masking.MaskProto(typ, rules)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, typepb.Syntax_SYNTAX_PROTO2, typ.Syntax)
+	assert.Equal(t, "", typ.Name)
+}
+
+func Test_MaskProto_WithInvalidRules_ReturnsError(t *testing.T) {
+	// arrange
+	testCases := map[string]masking.ProtoRules{
+		"invalid field name": {Fields: map[string]string{"google.protobuf..name": "X"}},
+		"invalid path":       {Paths: map[string]string{"methods[0].name": "X"}},
+	}
+
+	for name, rules := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// act
+			err := masking.MaskProto(&apipb.Api{Name: "acme.v1.Users"}, rules)
+
+			// assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_Mask_WithProtoMessage_IgnoresInternalState(t *testing.T) {
+	// arrange
+	api := &apipb.Api{Name: "acme.v1.Users"}
+
+	// act
+	err := masking.DeepMask(api)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "acme.v1.Users", api.Name)
+}
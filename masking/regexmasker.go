@@ -0,0 +1,124 @@
+package masking
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// regexCache holds compiled patterns of regex maskers by pattern.
+var regexCache sync.Map
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if re, found := regexCache.Load(pattern); found {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("regex: invalid pattern: %w", err)
+	}
+	regexCache.Store(pattern, re)
+	return re, nil
+}
+
+// regexMaskFuncBuilder returns the builder of the "regex" masker, which masks the matches of a
+// pattern within a string, leaving the rest of the string intact. It takes the arguments:
+//
+//	pattern=RE      the regular expression to match (required)
+//	replace=TPL     replace each match with the template TPL, which may refer to groups as in
+//	                regexp.Expand, such as "$1-XXXX"
+//	group=N         mask only capture group N of each match, rather than the whole match
+//	char=C          the mask character, 'X' by default
+//	showfront=N     reveal the first N characters of each masked span
+//	showback=N      reveal the last N characters of each masked span
+//	alphanumeric    mask only letters and digits of each masked span
+//
+// Commas within the pattern or template must be escaped with a backslash, such as
+// `mask:"regex,pattern=\\d{3\\,4}"`.
+func regexMaskFuncBuilder() maskFuncBuilder {
+	return createStringMaskFuncBuilder("regex", maskRegex)
+}
+
+func maskRegex(s *string, args ...string) error {
+	var pattern, replace string
+	hasReplace := false
+	group := 0
+	maskChar := 'X'
+	hasMaskChar := false
+	var simpleArgs []string
+
+	for _, arg := range args {
+		argName, argVal, _ := strings.Cut(arg, "=")
+		switch argName {
+		case "pattern":
+			pattern = argVal
+		case "replace":
+			replace, hasReplace = argVal, true
+		case "group":
+			var err error
+			group, err = strconv.Atoi(argVal)
+			if err != nil || group < 0 {
+				return fmt.Errorf("regex: unable to parse group value")
+			}
+		case "char":
+			if len([]rune(argVal)) != 1 {
+				return fmt.Errorf("regex: char must be a single character")
+			}
+			maskChar, hasMaskChar = []rune(argVal)[0], true
+		case "showfront", "showback", "alphanumeric":
+			simpleArgs = append(simpleArgs, arg)
+		default:
+			return fmt.Errorf("regex: unrecognized argument: \"%s\"", argName)
+		}
+	}
+
+	if pattern == "" {
+		return fmt.Errorf("regex: pattern is required")
+	}
+	re, err := compileRegex(pattern)
+	if err != nil {
+		return err
+	}
+	if group > re.NumSubexp() {
+		return fmt.Errorf("regex: pattern has no group %d", group)
+	}
+
+	if hasReplace {
+		if group != 0 || hasMaskChar || len(simpleArgs) > 0 {
+			return fmt.Errorf("regex: replace cannot be combined with group, char or reveal arguments")
+		}
+		*s = re.ReplaceAllString(*s, replace)
+		return nil
+	}
+
+	// check the reveal arguments even if there are no matches
+	var empty string
+	if err := maskSimpleWithArgs(&empty, maskChar, simpleArgs...); err != nil {
+		return fmt.Errorf("regex: %w", err)
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringSubmatchIndex(*s, -1) {
+		start, end := loc[2*group], loc[2*group+1]
+		if start < 0 {
+			// the group did not participate in the match
+			continue
+		}
+
+		span := (*s)[start:end]
+		if err := maskSimpleWithArgs(&span, maskChar, simpleArgs...); err != nil {
+			return fmt.Errorf("regex: %w", err)
+		}
+		sb.WriteString((*s)[last:start])
+		sb.WriteString(span)
+		last = end
+	}
+	sb.WriteString((*s)[last:])
+
+	*s = sb.String()
+	return nil
+}
@@ -0,0 +1,110 @@
+package masking_test
+
+import (
+	"testing"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MaskRegex_MasksEachMatch(t *testing.T) {
+	// arrange
+	type Message struct {
+		Body string `mask:"regex,pattern=\\d+"`
+	}
+	msg := Message{Body: "order 1234 shipped to unit 56"}
+
+	// act
+	err := masking.Mask(&msg)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "order XXXX shipped to unit XX", msg.Body)
+}
+
+func Test_MaskRegex_WithGroupAndChar_MasksOnlyGroup(t *testing.T) {
+	// arrange
+	type Login struct {
+		URL string `mask:"regex,pattern=(token=)([^&]+),group=2,char=*"`
+	}
+	login := Login{URL: "https://example.com/cb?token=abc123&state=xyz"}
+
+	// act
+	err := masking.Mask(&login)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/cb?token=******&state=xyz", login.URL)
+}
+
+func Test_MaskRegex_WithRevealArgs_RevealsWithinEachMatch(t *testing.T) {
+	// arrange
+	type Statement struct {
+		Text string `mask:"regex,pattern=\\b\\d{12\\,19}\\b,showback=4"`
+	}
+	statement := Statement{Text: "cards 4111111111111111 and 5500000000000004, ref 12345"}
+
+	// act
+	err := masking.Mask(&statement)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "cards XXXXXXXXXXXX1111 and XXXXXXXXXXXX0004, ref 12345", statement.Text)
+}
+
+func Test_MaskRegex_WithShortMatch_MasksWholeMatch(t *testing.T) {
+	// arrange
+	type Note struct {
+		Text string `mask:"regex,pattern=#\\w+,showfront=2,showback=2"`
+	}
+	note := Note{Text: "tags #a #abcdef"}
+
+	// act
+	err := masking.Mask(&note)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "tags XX #aXXXef", note.Text)
+}
+
+func Test_MaskRegex_WithReplace_ExpandsTemplate(t *testing.T) {
+	// arrange
+	type Contact struct {
+		Email string `mask:"regex,pattern=^([^@])[^@]*@(.*)$,replace=$1***@$2"`
+	}
+	contact := Contact{Email: "jane.doe@example.com"}
+
+	// act
+	err := masking.Mask(&contact)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "j***@example.com", contact.Email)
+}
+
+func Test_MaskRegex_WithInvalidArgs_ReturnsError(t *testing.T) {
+	// arrange
+	testCases := map[string]string{
+		"missing pattern":       "regex,char=*",
+		"invalid pattern":       "regex,pattern=(",
+		"missing group":         "regex,pattern=(a)b,group=2",
+		"invalid group":         "regex,pattern=a,group=x",
+		"long char":             "regex,pattern=a,char=**",
+		"replace with group":    "regex,pattern=(a),group=1,replace=b",
+		"negative reveal":       "regex,pattern=a,showfront=-1",
+		"unrecognized argument": "regex,pattern=a,mode=fast",
+	}
+
+	for name, maskTag := range testCases {
+		t.Run(name, func(t *testing.T) {
+			s := "abc"
+
+			// act
+			err := masking.MaskValue(&s, maskTag)
+
+			// assert
+			assert.Error(t, err)
+			assert.Equal(t, "abc", s)
+		})
+	}
+}
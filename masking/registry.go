@@ -0,0 +1,126 @@
+package masking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Registry holds the maskers and policies used to mask values.
+//
+// The package-level functions, such as Mask and RegisterMasker, use DefaultRegistry.
+type Registry struct {
+	maskFuncBuilders map[string]maskFuncBuilder
+	typeMaskers      map[reflect.Type]maskFunc
+	fieldRules       map[reflect.Type]map[string]string
+	policies         []*Policy
+	detectors        []Detector
+	tagKeys          []string
+	classPolicies    []*ClassPolicy
+}
+
+// DefaultRegistry is the Registry used by the package-level masking functions.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns a new Registry containing only the built-in maskers.
+func NewRegistry() *Registry {
+	r := &Registry{
+		maskFuncBuilders: builtinMaskFuncBuilders(),
+		typeMaskers:      make(map[reflect.Type]maskFunc),
+		fieldRules:       make(map[reflect.Type]map[string]string),
+		detectors:        DefaultDetectors(),
+		tagKeys:          []string{defaultTagKey},
+	}
+	r.maskFuncBuilders["detect"] = r.detectMaskFuncBuilder()
+	return r
+}
+
+// Mask applies masking to public fields of v using the maskers and policies of r.
+//
+// See the package-level Mask for details.
+func (r *Registry) Mask(v interface{}, opts ...Option) error {
+	return r.MaskContext(context.Background(), v, opts...)
+}
+
+// DeepMask applies masking to all public fields of v, including pointers and slices, using the
+// maskers and policies of r.
+func (r *Registry) DeepMask(v interface{}, opts ...Option) error {
+	return r.DeepMaskContext(context.Background(), v, opts...)
+}
+
+// MaskContext applies masking to public fields of v using the maskers and policies of r, passing ctx to
+// context-aware maskers.
+//
+// See the package-level MaskContext for details.
+func (r *Registry) MaskContext(ctx context.Context, v interface{}, opts ...Option) error {
+	return r.maskWith(ctx, v, defaultMaskOptions(), opts)
+}
+
+// DeepMaskContext applies masking to all public fields of v, including pointers and slices, using the
+// maskers and policies of r, passing ctx to context-aware maskers.
+func (r *Registry) DeepMaskContext(ctx context.Context, v interface{}, opts ...Option) error {
+	return r.maskWith(ctx, v, deepMaskOptions(), opts)
+}
+
+// maskWith applies masking to v with the options base and opts.
+func (r *Registry) maskWith(ctx context.Context, v interface{}, base maskOptions, opts []Option) error {
+	maskOpts, err := r.newMaskOptions(base, opts)
+	if err != nil {
+		return err
+	}
+	if err := maskOpts.validateIncludes(reflect.TypeOf(v)); err != nil {
+		return err
+	}
+
+	ptr := reflect.ValueOf(v)
+	err = maskOpts.registry.mask(ctx, ptr, "", maskOpts)
+	if err != nil && ptr.Kind() == reflect.Pointer && !ptr.IsNil() {
+		err = maskOpts.failClosed(ctx, ptr.Elem(), err)
+	}
+	if err != nil || maskOpts.errs == nil {
+		return err
+	}
+	return errors.Join(*maskOpts.errs...)
+}
+
+// MaskValue applies the masker of r given by maskTag to the value pointed to by ptr.
+func (r *Registry) MaskValue(ptr interface{}, maskTag string) error {
+	ptrVal := reflect.ValueOf(ptr)
+	if ptrVal.Kind() != reflect.Pointer || ptrVal.IsNil() {
+		return fmt.Errorf("mask: expected non-nil pointer argument")
+	}
+
+	maskFunc, err := r.getMaskFunc(maskTag)
+	if err != nil {
+		return err
+	}
+	return maskFunc(context.Background(), ptrVal)
+}
+
+// MaskMap applies masking to values of m based on their keys using the maskers of r.
+//
+// See the package-level MaskMap for details.
+func (r *Registry) MaskMap(m map[string]interface{}, policy KeyPolicy) error {
+	return r.maskKeys(m, policy, "")
+}
+
+// fieldMaskTag returns the masker tag for the ith field of struct type t, and true if the field is
+// revealed as is by an empty profile tag.
+//
+// The field's struct tags given by tagKeys and profile take precedence, followed by field rules,
+// policies and then class policies of r.
+func (r *Registry) fieldMaskTag(t reflect.Type, i int, tagKeys []string, profile string) (string, bool, error) {
+	field := t.Field(i)
+	if maskTag, found := structMaskTag(field, tagKeys, profile); found {
+		return maskTag, maskTag == "", nil
+	}
+	if maskTag := r.fieldRules[t][field.Name]; maskTag != "" {
+		return maskTag, false, nil
+	}
+	if maskTag := r.policyMaskTag(t, field.Name); maskTag != "" {
+		return maskTag, false, nil
+	}
+	maskTag, classified, err := r.classMaskTag(field, profile)
+	return maskTag, classified && maskTag == "" && err == nil, err
+}
@@ -0,0 +1,122 @@
+package masking
+
+import (
+	"context"
+	"reflect"
+	"unicode/utf8"
+)
+
+// Report describes the fields reached while masking a value, for use as evidence of masking in audit
+// logs. A Report never holds the values of fields, masked or otherwise, and may be marshaled to JSON.
+type Report struct {
+	Fields []FieldReport `json:"fields"`
+}
+
+// FieldReport describes the masking of a single field, slice item or map value.
+type FieldReport struct {
+	// Path is the field path of the value, such as "Orders[3].Card.Number". Map keys are written as "*",
+	// such as "Users[*].SSN", since keys may hold sensitive values.
+	Path string `json:"path"`
+	// Masker is the masker tag applied to the value, such as "X,showback=4", "self" for a SelfMasker or
+	// MaskedValuer, or "type:T" for the type masker of type T.
+	Masker string `json:"masker,omitempty"`
+	// Kind is the kind of the value before masking, such as "string" or "struct".
+	Kind string `json:"kind"`
+	// Length is the length of the value before masking in characters for strings, or in elements for
+	// slices, arrays and maps.
+	Length int `json:"length"`
+	// Status is one of "masked", "skipped" or "error".
+	Status string `json:"status"`
+	// Reason is the reason the value was skipped, or the error of its masker. Masker errors are included
+	// as is, so maskers must not include values in their errors.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Statuses of a FieldReport.
+const (
+	StatusMasked  = "masked"
+	StatusSkipped = "skipped"
+	StatusError   = "error"
+)
+
+// Reasons of a skipped FieldReport.
+const (
+	// ReasonConditionNotMet is given for a field whose tag conditions do not hold.
+	ReasonConditionNotMet = "condition not met"
+	// ReasonExcluded is given for a field excluded by Exclude or revealed by an empty profile tag.
+	ReasonExcluded = "excluded"
+	// ReasonPointerNotFollowed is given for a non-nil pointer that was not followed, as by Mask.
+	ReasonPointerNotFollowed = "pointer not followed"
+	// ReasonSliceNotFollowed is given for a non-empty slice whose items were not masked, as by Mask.
+	ReasonSliceNotFollowed = "slice not followed"
+	// ReasonMapNotFollowed is given for a non-empty map whose values were not masked.
+	ReasonMapNotFollowed = "map not followed"
+	// ReasonInterfaceNotFollowed is given for a non-nil interface whose value was not masked.
+	ReasonInterfaceNotFollowed = "interface not followed"
+)
+
+// MaskWithReport applies masking to v as MaskWith does and returns a report of the fields reached.
+//
+// The report is returned even if masking fails, in which case it describes the fields reached before
+// the failure.
+func MaskWithReport(v interface{}, opts ...Option) (*Report, error) {
+	return DefaultRegistry.MaskWithReport(v, opts...)
+}
+
+// MaskWithReport applies masking to v using the maskers and policies of r and returns a report of the
+// fields reached.
+//
+// See the package-level MaskWithReport for details.
+func (r *Registry) MaskWithReport(v interface{}, opts ...Option) (*Report, error) {
+	report := &Report{Fields: []FieldReport{}}
+	base := defaultMaskOptions()
+	base.report = report
+	err := r.maskWith(context.Background(), v, base, opts)
+	return report, err
+}
+
+// reportEntry returns a field report for val at path, to be completed once val is masked, if opts
+// have a report.
+func (opts maskOptions) reportEntry(path string, val reflect.Value, masker string) *FieldReport {
+	if opts.report == nil {
+		return nil
+	}
+	return &FieldReport{
+		Path:   path,
+		Masker: masker,
+		Kind:   val.Kind().String(),
+		Length: valueLength(val),
+	}
+}
+
+// recordResult adds entry, if not nil, to the report of opts as masked, or with the error err.
+func (opts maskOptions) recordResult(entry *FieldReport, err error) {
+	if entry == nil {
+		return
+	}
+	entry.Status = StatusMasked
+	if err != nil {
+		entry.Status, entry.Reason = StatusError, err.Error()
+	}
+	opts.report.Fields = append(opts.report.Fields, *entry)
+}
+
+// recordSkip adds a field report for val at path to the report of opts, if any, as skipped for reason.
+func (opts maskOptions) recordSkip(path string, val reflect.Value, masker, reason string) {
+	if entry := opts.reportEntry(path, val, masker); entry != nil {
+		entry.Status, entry.Reason = StatusSkipped, reason
+		opts.report.Fields = append(opts.report.Fields, *entry)
+	}
+}
+
+// valueLength returns the length of val in characters for strings, or in elements for slices, arrays
+// and maps, and otherwise 0.
+func valueLength(val reflect.Value) int {
+	switch val.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(val.String())
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return val.Len()
+	}
+	return 0
+}
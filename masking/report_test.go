@@ -0,0 +1,178 @@
+package masking_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MaskWithReport_WithStruct_ReportsMaskedAndSkippedFields(t *testing.T) {
+	// arrange
+	type Card struct {
+		Number  string `mask:"X,showback=4"`
+		Holder  string `mask:"*,if=Country==US"`
+		Country string
+	}
+	type Account struct {
+		Email   string `mask:"X"`
+		Card    Card
+		Backup  *Card
+		Aliases []string
+		Notes   string
+	}
+	account := Account{
+		Email:   "jane@example.com",
+		Card:    Card{Number: "4111111111111111", Holder: "Jane Doe", Country: "CA"},
+		Backup:  &Card{Number: "5500000000000004"},
+		Aliases: []string{"jd"},
+	}
+
+	// act
+	report, err := masking.MaskWithReport(&account)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []masking.FieldReport{
+		{Path: "Email", Masker: "X", Kind: "string", Length: 16, Status: masking.StatusMasked},
+		{Path: "Card.Number", Masker: "X,showback=4", Kind: "string", Length: 16, Status: masking.StatusMasked},
+		{
+			Path: "Card.Holder", Masker: "*,if=Country==US", Kind: "string", Length: 8,
+			Status: masking.StatusSkipped, Reason: masking.ReasonConditionNotMet,
+		},
+		{Path: "Backup", Kind: "ptr", Status: masking.StatusSkipped, Reason: masking.ReasonPointerNotFollowed},
+		{
+			Path: "Aliases", Kind: "slice", Length: 1,
+			Status: masking.StatusSkipped, Reason: masking.ReasonSliceNotFollowed,
+		},
+	}, report.Fields)
+}
+
+func Test_MaskWithReport_WithDeepOptions_ReportsFollowedValues(t *testing.T) {
+	// arrange
+	type Card struct {
+		Number string `mask:"X,showback=4"`
+	}
+	type Account struct {
+		Email   string `mask:"X"`
+		Backup  *Card
+		Aliases []string
+	}
+	account := Account{
+		Email:   "jane@example.com",
+		Backup:  &Card{Number: "5500000000000004"},
+		Aliases: []string{"jd"},
+	}
+
+	// act
+	report, err := masking.MaskWithReport(&account, masking.Pointers(true), masking.Slices(true))
+
+	// assert
+	assert.NoError(t, err)
+	paths := []string{}
+	for _, field := range report.Fields {
+		paths = append(paths, field.Path)
+	}
+	assert.Equal(t, []string{"Email", "Backup.Number"}, paths)
+	assert.Equal(t, "XXXXXXXXXXXX0004", account.Backup.Number)
+}
+
+func Test_MaskWithReport_WithExclude_ReportsExcludedFields(t *testing.T) {
+	// arrange
+	type Account struct {
+		Email string `mask:"X"`
+	}
+	account := Account{Email: "jane@example.com"}
+
+	// act
+	report, err := masking.MaskWithReport(&account, masking.Exclude("Email"))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []masking.FieldReport{{
+		Path: "Email", Kind: "string", Length: 16, Status: masking.StatusSkipped, Reason: masking.ReasonExcluded,
+	}}, report.Fields)
+	assert.Equal(t, "jane@example.com", account.Email)
+}
+
+func Test_MaskWithReport_WithMaskerError_ReportsError(t *testing.T) {
+	// arrange
+	r := masking.NewRegistry()
+	registerErr := masking.RegisterMaskerIn(r, "fail", func(s *string, _ ...string) error {
+		return errors.New("unsupported format")
+	})
+	assert.NoError(t, registerErr)
+	v := struct {
+		Code  string `mask:"fail"`
+		Other string `mask:"X"`
+	}{Code: "abc", Other: "def"}
+
+	// act
+	report, err := r.MaskWithReport(&v, masking.FailClosed(true))
+
+	// assert
+	assert.Error(t, err)
+	assert.Equal(t, masking.StatusError, report.Fields[0].Status)
+	assert.Equal(t, "Code", report.Fields[0].Path)
+	assert.Contains(t, report.Fields[0].Reason, "unsupported format")
+	assert.Equal(t, masking.StatusMasked, report.Fields[1].Status)
+	assert.Equal(t, "", v.Code)
+}
+
+func Test_MaskWithReport_MarshaledToJSON_HoldsNoRawValues(t *testing.T) {
+	// arrange
+	type Card struct {
+		Number string `mask:"X,showback=4"`
+		Holder string `mask:"*"`
+	}
+	type Account struct {
+		Email   string `mask:"X"`
+		Card    Card
+		Backup  *Card
+		Aliases []string
+	}
+	account := Account{
+		Email:   "jane@example.com",
+		Card:    Card{Number: "4111111111111111", Holder: "Jane Doe"},
+		Backup:  &Card{Number: "5500000000000004"},
+		Aliases: []string{"jd"},
+	}
+
+	// act
+	report, err := masking.MaskWithReport(&account)
+	data, marshalErr := json.Marshal(report)
+
+	// assert
+	assert.NoError(t, err)
+	assert.NoError(t, marshalErr)
+	for _, raw := range []string{"jane@example.com", "4111111111111111", "Jane Doe", "5500000000000004", "jd"} {
+		assert.NotContains(t, string(data), raw)
+	}
+	assert.Contains(t, string(data), `"path":"Card.Number","masker":"X,showback=4","kind":"string","length":16`)
+}
+
+func Test_MaskWithReport_WithMaps_LeavesMapKeysOutOfPaths(t *testing.T) {
+	// arrange
+	type User struct {
+		SSN string `mask:"X,showback=4"`
+	}
+	type Directory struct {
+		Users map[string]User
+	}
+	directory := Directory{Users: map[string]User{"alice@example.com": {SSN: "123-45-6789"}}}
+
+	// act
+	report, err := masking.MaskWithReport(&directory, masking.Maps(true))
+	data, marshalErr := json.Marshal(report)
+
+	// assert
+	assert.NoError(t, err)
+	assert.NoError(t, marshalErr)
+	assert.Equal(t, []masking.FieldReport{
+		{Path: "Users[*].SSN", Masker: "X,showback=4", Kind: "string", Length: 11, Status: masking.StatusMasked},
+	}, report.Fields)
+	assert.NotContains(t, string(data), "alice@example.com")
+	assert.Equal(t, "XXXXXXX6789", directory.Users["alice@example.com"].SSN)
+}
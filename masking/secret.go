@@ -0,0 +1,111 @@
+package masking
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+)
+
+// redacted is rendered in place of secrets that have no masker or cannot be masked.
+const redacted = "[REDACTED]"
+
+// Secret holds a value of type T that is only ever rendered in masked form.
+//
+// Printing, logging and marshaling a Secret all produce the masked form of its value. The value is
+// first formatted as with fmt.Sprint and then masked using the secret's mask tag. If the secret has no
+// mask tag, or masking fails, "[REDACTED]" is rendered instead. Reveal is the only way to read the
+// unmasked value.
+type Secret[T any] struct {
+	value   T
+	maskTag string
+}
+
+// secret is implemented by every Secret, whose value is never masked in place so that it remains
+// available to Reveal.
+type secret interface {
+	isSecret()
+}
+
+var secretType = reflect.TypeOf((*secret)(nil)).Elem()
+
+func (s Secret[T]) isSecret() {}
+
+// NewSecret returns a Secret holding value that is rendered using the masker given by maskTag, such as
+// "X,showback=4". An empty maskTag renders the secret as "[REDACTED]".
+func NewSecret[T any](value T, maskTag string) Secret[T] {
+	return Secret[T]{
+		value:   value,
+		maskTag: maskTag,
+	}
+}
+
+// Reveal returns the unmasked value of s.
+func (s Secret[T]) Reveal() T {
+	return s.value
+}
+
+// String returns the masked form of s.
+func (s Secret[T]) String() string {
+	if s.maskTag == "" {
+		return redacted
+	}
+
+	maskFunc, err := DefaultRegistry.getMaskFunc(s.maskTag)
+	if err != nil {
+		return redacted
+	}
+
+	// mask the formatted value so that masking never reaches the underlying value
+	str := fmt.Sprint(s.value)
+	err = maskFunc(context.Background(), reflect.ValueOf(&str))
+	if err != nil {
+		return redacted
+	}
+	return str
+}
+
+// GoString returns the masked form of s as Go syntax.
+func (s Secret[T]) GoString() string {
+	var zero T
+	return fmt.Sprintf("masking.Secret[%T](%s)", zero, strconv.Quote(s.String()))
+}
+
+// Format formats the masked form of s.
+//
+// The %#v verb formats s as with GoString. All other verbs format the masked form as a string.
+func (s Secret[T]) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if f.Flag('#') {
+			fmt.Fprint(f, s.GoString())
+			return
+		}
+	case 's', 'q':
+	default:
+		verb = 's'
+	}
+	fmt.Fprintf(f, fmt.FormatString(f, verb), s.String())
+}
+
+// MarshalJSON returns the masked form of s as a JSON string.
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// MarshalText returns the masked form of s.
+func (s Secret[T]) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// MarshalYAML returns the masked form of s for YAML encoders such as gopkg.in/yaml.v3.
+func (s Secret[T]) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+// LogValue returns the masked form of s for structured logging with log/slog.
+func (s Secret[T]) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
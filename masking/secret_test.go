@@ -0,0 +1,139 @@
+package masking_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_Secret_WithFmt_PrintsMaskedValue(t *testing.T) {
+	// arrange
+	s := masking.NewSecret("1234567890", "X,showback=4")
+	type TestCase struct {
+		Format   string
+		Expected string
+	}
+	testCases := []TestCase{
+		{Format: "%v", Expected: "XXXXXX7890"},
+		{Format: "%+v", Expected: "XXXXXX7890"},
+		{Format: "%s", Expected: "XXXXXX7890"},
+		{Format: "%q", Expected: `"XXXXXX7890"`},
+		{Format: "%12s", Expected: "  XXXXXX7890"},
+		{Format: "%d", Expected: "XXXXXX7890"},
+		{Format: "%#v", Expected: `masking.Secret[string]("XXXXXX7890")`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Format, func(t *testing.T) {
+			// act
+			str := fmt.Sprintf(tc.Format, s)
+
+			// assert
+			assert.Equal(t, tc.Expected, str)
+		})
+	}
+}
+
+func Test_Secret_WithoutMaskTag_PrintsRedacted(t *testing.T) {
+	// arrange
+	s := masking.NewSecret(42, "")
+
+	// act
+	str := fmt.Sprint(s)
+
+	// assert
+	assert.Equal(t, "[REDACTED]", str)
+}
+
+func Test_Secret_WithNonStringValue_MasksFormattedValue(t *testing.T) {
+	// arrange
+	s := masking.NewSecret(123456789, "*,showback=2")
+
+	// act
+	str := s.String()
+
+	// assert
+	assert.Equal(t, "*******89", str)
+	assert.Equal(t, 123456789, s.Reveal())
+}
+
+func Test_Secret_WithUnrecognizedMaskFunc_PrintsRedacted(t *testing.T) {
+	// arrange
+	s := masking.NewSecret("hunter2", "idk")
+
+	// act
+	str := s.String()
+
+	// assert
+	assert.Equal(t, "[REDACTED]", str)
+}
+
+func Test_Secret_WithMarshalers_MarshalsMaskedValue(t *testing.T) {
+	// arrange
+	type Credentials struct {
+		User     string                 `json:"user" yaml:"user"`
+		Password masking.Secret[string] `json:"password" yaml:"password"`
+	}
+	c := Credentials{
+		User:     "jsmith",
+		Password: masking.NewSecret("hunter2", "*"),
+	}
+
+	// act
+	jsonBytes, jsonErr := json.Marshal(c)
+	yamlBytes, yamlErr := yaml.Marshal(c)
+	textBytes, textErr := c.Password.MarshalText()
+
+	// assert
+	assert.NoError(t, jsonErr)
+	assert.Equal(t, `{"user":"jsmith","password":"*******"}`, string(jsonBytes))
+	assert.NoError(t, yamlErr)
+	assert.Equal(t, "user: jsmith\npassword: '*******'\n", string(yamlBytes))
+	assert.NoError(t, textErr)
+	assert.Equal(t, "*******", string(textBytes))
+}
+
+func Test_Secret_WithSlog_LogsMaskedValue(t *testing.T) {
+	// arrange
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	// act
+	logger.Info("login", "token", masking.NewSecret("abcdef", "X"))
+
+	// assert
+	assert.Equal(t, "level=INFO msg=login token=XXXXXX\n", buf.String())
+}
+
+func Test_Mask_OnStructWithSecret_LeavesSecretValue(t *testing.T) {
+	// arrange
+	type Inner struct {
+		Secret string `mask:"X"`
+	}
+	type S struct {
+		Inner masking.Secret[Inner]
+	}
+	s := S{
+		Inner: masking.NewSecret(Inner{Secret: "hunter2"}, ""),
+	}
+
+	// act
+	err := masking.Mask(&s)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, Inner{Secret: "hunter2"}, s.Inner.Reveal())
+}
@@ -0,0 +1,74 @@
+package masking
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// SelfMasker is implemented by types that mask their own sensitive data in place.
+//
+// When masking reaches a value implementing SelfMasker, MaskSensitive is called in place of traversing
+// the value. Untagged values receive no arguments; the "self" masker may be used in a tag to pass
+// arguments, such as `mask:"self,showback=4"`. Implementations must not call Mask on the receiver.
+type SelfMasker interface {
+	MaskSensitive(args ...string) error
+}
+
+// MaskedValuer is implemented by types that return a masked copy of themselves.
+//
+// When masking reaches a value implementing MaskedValuer, the value is replaced by the result of
+// Masked, which must be assignable to the value's type. SelfMasker takes precedence if a type
+// implements both.
+type MaskedValuer interface {
+	Masked() interface{}
+}
+
+var (
+	selfMaskerType   = reflect.TypeOf((*SelfMasker)(nil)).Elem()
+	maskedValuerType = reflect.TypeOf((*MaskedValuer)(nil)).Elem()
+)
+
+func selfMaskFuncBuilder() maskFuncBuilder {
+	return func(args ...string) maskFunc {
+		return func(_ context.Context, ptr reflect.Value) error {
+			selfMasker, ok := ptr.Interface().(SelfMasker)
+			if !ok {
+				return fmt.Errorf("self: type %s does not implement SelfMasker", ptr.Type().Elem())
+			}
+			return selfMasker.MaskSensitive(args...)
+		}
+	}
+}
+
+// maskSelf applies the SelfMasker or MaskedValuer implementation of the value pointed to by ptr, and
+// returns false if the value implements neither.
+func maskSelf(ptr reflect.Value) (bool, error) {
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || !ptr.CanInterface() {
+		return false, nil
+	}
+
+	switch v := ptr.Interface().(type) {
+	case SelfMasker:
+		return true, v.MaskSensitive()
+	case MaskedValuer:
+		return true, setMasked(ptr, v.Masked())
+	}
+	return false, nil
+}
+
+func setMasked(ptr reflect.Value, masked interface{}) error {
+	val := ptr.Elem()
+
+	maskedVal := reflect.ValueOf(masked)
+	if !maskedVal.IsValid() {
+		val.Set(reflect.Zero(val.Type()))
+		return nil
+	}
+	if !maskedVal.Type().AssignableTo(val.Type()) {
+		return fmt.Errorf("mask: Masked returned %s, expected %s", maskedVal.Type(), val.Type())
+	}
+
+	val.Set(maskedVal)
+	return nil
+}
@@ -0,0 +1,105 @@
+package masking_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/stretchr/testify/assert"
+)
+
+type CardNumber struct {
+	Number string
+}
+
+func (c *CardNumber) MaskSensitive(args ...string) error {
+	show := 0
+	if len(args) > 0 && args[0] == "last4" {
+		show = 4
+	}
+	masked := len(c.Number) - show
+	c.Number = strings.Repeat("#", masked) + c.Number[masked:]
+	return nil
+}
+
+type Email string
+
+func (e Email) Masked() interface{} {
+	at := strings.Index(string(e), "@")
+	if at < 0 {
+		return Email("***")
+	}
+	return Email("***" + string(e)[at:])
+}
+
+func Test_Mask_WithSelfMasker_CallsMaskSensitive(t *testing.T) {
+	// arrange
+	type Payment struct {
+		Card CardNumber
+	}
+	p := Payment{
+		Card: CardNumber{Number: "4111111111111111"},
+	}
+
+	// act
+	err := masking.Mask(&p)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "################", p.Card.Number)
+}
+
+func Test_Mask_WithSelfMaskerTag_PassesArgs(t *testing.T) {
+	// arrange
+	type Payment struct {
+		Card CardNumber `mask:"self,last4"`
+	}
+	p := Payment{
+		Card: CardNumber{Number: "4111111111111111"},
+	}
+
+	// act
+	err := masking.Mask(&p)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "############1111", p.Card.Number)
+}
+
+func Test_Mask_WithSelfTagOnNonSelfMasker_ReturnsError(t *testing.T) {
+	// arrange
+	type S struct {
+		Secret string `mask:"self"`
+	}
+	s := S{
+		Secret: "hunter2",
+	}
+
+	// act
+	err := masking.Mask(&s)
+
+	// assert
+	assert.Error(t, err)
+}
+
+func Test_Mask_WithMaskedValuer_ReplacesValue(t *testing.T) {
+	// arrange
+	type Contact struct {
+		Email  Email
+		Emails []Email
+	}
+	c := Contact{
+		Email:  "jane@example.com",
+		Emails: []Email{"john@example.com", "invalid"},
+	}
+
+	// act
+	err := masking.DeepMask(&c)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, Contact{
+		Email:  "***@example.com",
+		Emails: []Email{"***@example.com", "***"},
+	}, c)
+}
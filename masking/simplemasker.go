@@ -68,6 +68,10 @@ func maskSimpleWithArgs(s *string, maskChar rune, args ...string) error {
 		}
 	}
 
+	if showFront < 0 || showBack < 0 {
+		return fmt.Errorf("showfront and showback values must not be negative")
+	}
+
 	maskSimple(s, maskChar, showFront, showBack, alnumOnly)
 	return nil
 }
@@ -90,6 +94,11 @@ func maskSimple(s *string, maskChar rune, showFront, showBack int, alnumOnly boo
 	oldS := *s
 	lenS := len(oldS)
 
+	// mask the whole string if it is too short to reveal the requested sections
+	if showFront+showBack > lenS {
+		showFront, showBack = 0, 0
+	}
+
 	prefix := oldS[0:showFront]
 	suffix := oldS[lenS-showBack:]
 	midMasked := strings.Map(charMasker, oldS[showFront:lenS-showBack])
@@ -140,3 +140,15 @@ func Test_MaskSimple_WithNonStandardChar_ReturnsCorrectResult(t *testing.T) {
 	// assert
 	assert.Equal(t, expectedMask, ui.PhoneNumber)
 }
+
+func Test_MaskSimple_WithRevealLongerThanValue_MasksWholeValue(t *testing.T) {
+	// arrange
+	s := "123"
+
+	// act
+	err := masking.MaskValue(&s, "X,showfront=2,showback=2")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "XXX", s)
+}
@@ -0,0 +1,305 @@
+package sqlmask
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// conn wraps a driver connection so that the rows of its queries are masked.
+//
+// Optional interfaces of the wrapped connection are forwarded. Where the wrapped connection does not
+// implement one, conn falls back to the behavior database/sql would use without it.
+type conn struct {
+	driver.Conn
+	cfg *config
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	s, err := c.Conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: s, conn: c}, nil
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	pc, ok := c.Conn.(driver.ConnPrepareContext)
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return c.Prepare(query)
+	}
+
+	s, err := pc.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: s, conn: c}, nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		// database/sql prepares a statement instead
+		return nil, driver.ErrSkip
+	}
+
+	r, err := qc.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(r, c.cfg), nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		// database/sql prepares a statement instead
+		return nil, driver.ErrSkip
+	}
+	return ec.ExecContext(ctx, query, args)
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bt, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return bt.BeginTx(ctx, opts)
+	}
+
+	if opts.Isolation != 0 {
+		return nil, errorf("driver does not support non-default isolation level")
+	}
+	if opts.ReadOnly {
+		return nil, errorf("driver does not support read-only transactions")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.Conn.Begin()
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if sr, ok := c.Conn.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// stmt wraps a prepared statement so that the rows of its queries are masked.
+type stmt struct {
+	driver.Stmt
+	conn *conn
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	r, err := s.Stmt.Query(args)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(r, s.conn.cfg), nil
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	var r driver.Rows
+	var err error
+	if sq, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		r, err = sq.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		values, err = namedValuesToValues(args)
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err == nil {
+			r, err = s.Stmt.Query(values)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newRows(r, s.conn.cfg), nil
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if se, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return se.ExecContext(ctx, args)
+	}
+
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values)
+}
+
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return s.conn.CheckNamedValue(nv)
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errorf("driver does not support named parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+// rows wraps driver rows, masking the values of columns selected by the column policy.
+type rows struct {
+	driver.Rows
+	cfg *config
+	// maskTags holds the masker tag of each column, or "" for columns that are not masked.
+	maskTags []string
+}
+
+func newRows(r driver.Rows, cfg *config) *rows {
+	columns := r.Columns()
+	maskTags := make([]string, len(columns))
+	for i, column := range columns {
+		maskTags[i], _ = cfg.columns.Match(column)
+	}
+	return &rows{
+		Rows:     r,
+		cfg:      cfg,
+		maskTags: maskTags,
+	}
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if err := r.Rows.Next(dest); err != nil {
+		return err
+	}
+
+	for i, maskTag := range r.maskTags {
+		if maskTag == "" || i >= len(dest) || dest[i] == nil {
+			continue
+		}
+		s := valueString(dest[i])
+		if err := r.cfg.registry.MaskValue(&s, maskTag); err != nil {
+			// do not return the unmasked row
+			for j := range dest {
+				dest[j] = nil
+			}
+			return errorf("column \"%s\": %w", r.Columns()[i], err)
+		}
+		dest[i] = s
+	}
+	return nil
+}
+
+// valueString formats a driver value as text for masking.
+func valueString(v driver.Value) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (r *rows) HasNextResultSet() bool {
+	if rs, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rs.HasNextResultSet()
+	}
+	return false
+}
+
+func (r *rows) NextResultSet() error {
+	rs, ok := r.Rows.(driver.RowsNextResultSet)
+	if !ok {
+		return io.EOF
+	}
+	if err := rs.NextResultSet(); err != nil {
+		return err
+	}
+
+	// the next result set may have different columns
+	next := newRows(r.Rows, r.cfg)
+	r.maskTags = next.maskTags
+	return nil
+}
+
+var stringType = reflect.TypeOf("")
+
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	if r.maskTags[index] != "" {
+		return stringType
+	}
+	if ct, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return ct.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return ct.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *rows) ColumnTypeLength(index int) (int64, bool) {
+	if r.maskTags[index] != "" {
+		// masking may change the length of values
+		return 0, false
+	}
+	if ct, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return ct.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+func (r *rows) ColumnTypeNullable(index int) (bool, bool) {
+	if ct, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return ct.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+func (r *rows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	if r.maskTags[index] != "" {
+		return 0, 0, false
+	}
+	if ct, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return ct.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
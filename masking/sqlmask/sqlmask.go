@@ -0,0 +1,139 @@
+// Package sqlmask provides a database/sql driver wrapper that masks column values of query results.
+//
+// Columns are matched by name against a key policy, so that values are masked before they reach the
+// caller:
+//
+//	connector := sqlmask.WrapConnector(pgConnector, sqlmask.Config{
+//		Columns: &masking.KeyPolicy{
+//			Rules: []masking.KeyRule{
+//				{Key: "email", Mask: "X"},
+//				{Key: "*_ssn", Mask: "X,showback=4"},
+//			},
+//			IgnoreCase: true,
+//		},
+//	})
+//	db := sql.OpenDB(connector)
+package sqlmask
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/dgravesa/go-mask/masking"
+)
+
+func errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("sqlmask: "+format, args...)
+}
+
+// Config configures the masking of query results.
+type Config struct {
+	// Columns selects the masker for each result column by column name. If nil,
+	// masking.DefaultKeyPolicy is used.
+	//
+	// Values of masked columns are returned as strings. Values that are not strings or byte slices are
+	// formatted as text before masking.
+	Columns *masking.KeyPolicy
+	// Registry provides the maskers. If nil, masking.DefaultRegistry is used.
+	Registry *masking.Registry
+}
+
+// config is the resolved form of Config shared by the wrapped driver values.
+type config struct {
+	columns  masking.KeyPolicy
+	registry *masking.Registry
+}
+
+func newConfig(cfg Config) *config {
+	c := &config{
+		columns:  masking.DefaultKeyPolicy,
+		registry: cfg.Registry,
+	}
+	if cfg.Columns != nil {
+		c.columns = *cfg.Columns
+	}
+	if c.registry == nil {
+		c.registry = masking.DefaultRegistry
+	}
+	return c
+}
+
+// Driver is a driver.Driver that masks the query results of a wrapped driver.
+type Driver struct {
+	driver driver.Driver
+	cfg    *config
+}
+
+// Wrap returns a driver that masks the query results of d as configured by cfg. The returned driver
+// may be registered with sql.Register.
+func Wrap(d driver.Driver, cfg Config) *Driver {
+	return &Driver{
+		driver: d,
+		cfg:    newConfig(cfg),
+	}
+}
+
+// Open opens a connection of the wrapped driver.
+func (d *Driver) Open(name string) (driver.Conn, error) {
+	c, err := d.driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c, cfg: d.cfg}, nil
+}
+
+// OpenConnector returns a connector of the wrapped driver.
+func (d *Driver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.driver.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &connector{connector: c, driver: d}, nil
+	}
+	return &connector{connector: dsnConnector{name: name, driver: d.driver}, driver: d}, nil
+}
+
+// WrapConnector returns a connector that masks the query results of c as configured by cfg, for use
+// with sql.OpenDB.
+func WrapConnector(c driver.Connector, cfg Config) driver.Connector {
+	return &connector{
+		connector: c,
+		driver: &Driver{
+			driver: c.Driver(),
+			cfg:    newConfig(cfg),
+		},
+	}
+}
+
+type connector struct {
+	connector driver.Connector
+	driver    *Driver
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dc, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: dc, cfg: c.driver.cfg}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// dsnConnector is a connector for drivers that do not implement driver.DriverContext.
+type dsnConnector struct {
+	name   string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
@@ -0,0 +1,221 @@
+package sqlmask_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/dgravesa/go-mask/masking/sqlmask"
+)
+
+// fakeDriver is a minimal driver whose queries all return the same table. It implements only the
+// required driver interfaces.
+type fakeDriver struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	return fakeConn{d}, nil
+}
+
+func (d *fakeDriver) Connect(context.Context) (driver.Conn, error) {
+	return fakeConn{d}, nil
+}
+
+func (d *fakeDriver) Driver() driver.Driver {
+	return d
+}
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return fakeStmt(c), nil }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	d *fakeDriver
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{d: s.d}, nil
+}
+
+type fakeRows struct {
+	d *fakeDriver
+	i int
+}
+
+func (r *fakeRows) Columns() []string { return r.d.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.d.rows) {
+		return io.EOF
+	}
+	copy(dest, r.d.rows[r.i])
+	r.i++
+	return nil
+}
+
+func openDB(t *testing.T, d *fakeDriver, cfg sqlmask.Config) *sql.DB {
+	db := sql.OpenDB(sqlmask.WrapConnector(d, cfg))
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func Test_WrapConnector_WithColumnRules_MasksMatchingColumns(t *testing.T) {
+	// arrange
+	d := &fakeDriver{
+		columns: []string{"id", "email", "customer_ssn", "pin", "note"},
+		rows: [][]driver.Value{
+			{int64(1), "jane@example.com", []byte("123-45-6789"), int64(1234), nil},
+			{int64(2), nil, []byte("987-65-4321"), int64(98765), "vip"},
+		},
+	}
+	db := openDB(t, d, sqlmask.Config{
+		Columns: &masking.KeyPolicy{
+			Rules: []masking.KeyRule{
+				{Key: "email", Mask: "X"},
+				{Key: "*_ssn", Mask: "X,showback=4"},
+				{Key: "pin", Mask: "*"},
+			},
+		},
+	})
+
+	// act
+	rows, err := db.Query("SELECT id, email, customer_ssn, pin, note FROM customers WHERE id > ?", 0)
+	assert.NoError(t, err)
+	defer rows.Close()
+	type customer struct {
+		id    int64
+		email sql.NullString
+		ssn   string
+		pin   string
+		note  sql.NullString
+	}
+	var customers []customer
+	for rows.Next() {
+		var c customer
+		assert.NoError(t, rows.Scan(&c.id, &c.email, &c.ssn, &c.pin, &c.note))
+		customers = append(customers, c)
+	}
+
+	// assert
+	assert.NoError(t, rows.Err())
+	assert.Equal(t, []customer{
+		{id: 1, email: sql.NullString{String: "XXXXXXXXXXXXXXXX", Valid: true}, ssn: "XXXXXXX6789", pin: "****"},
+		{id: 2, ssn: "XXXXXXX4321", pin: "*****", note: sql.NullString{String: "vip", Valid: true}},
+	}, customers)
+}
+
+func Test_WrapConnector_WithoutColumnRules_UsesDefaultKeyPolicy(t *testing.T) {
+	// arrange
+	d := &fakeDriver{
+		columns: []string{"username", "password"},
+		rows:    [][]driver.Value{{"jsmith", "hunter2"}},
+	}
+	db := openDB(t, d, sqlmask.Config{})
+	var username, password string
+
+	// act
+	err := db.QueryRow("SELECT username, password FROM users").Scan(&username, &password)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "jsmith", username)
+	assert.Equal(t, "*******", password)
+}
+
+func Test_WrapConnector_WithUnknownMasker_ReturnsErrorInsteadOfValue(t *testing.T) {
+	// arrange
+	d := &fakeDriver{
+		columns: []string{"email"},
+		rows:    [][]driver.Value{{"jane@example.com"}},
+	}
+	db := openDB(t, d, sqlmask.Config{
+		Columns: &masking.KeyPolicy{
+			Rules: []masking.KeyRule{{Key: "email", Mask: "unregistered"}},
+		},
+	})
+	var email string
+
+	// act
+	err := db.QueryRow("SELECT email FROM users").Scan(&email)
+
+	// assert
+	assert.Error(t, err)
+	assert.Equal(t, "", email)
+}
+
+func Test_WrapConnector_ColumnTypes_ReportMaskedColumnsAsStrings(t *testing.T) {
+	// arrange
+	d := &fakeDriver{
+		columns: []string{"id", "pin"},
+		rows:    [][]driver.Value{{int64(1), int64(1234)}},
+	}
+	db := openDB(t, d, sqlmask.Config{
+		Columns: &masking.KeyPolicy{
+			Rules: []masking.KeyRule{{Key: "pin", Mask: "*"}},
+		},
+	})
+	rows, err := db.Query("SELECT id, pin FROM users")
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	// act
+	columnTypes, err := rows.ColumnTypes()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, reflect.TypeOf(new(interface{})).Elem(), columnTypes[0].ScanType())
+	assert.Equal(t, reflect.TypeOf(""), columnTypes[1].ScanType())
+}
+
+func Test_Wrap_WithRegisteredDriver_MasksQueriesAndPassesThroughExecAndTx(t *testing.T) {
+	// arrange
+	d := &fakeDriver{
+		columns: []string{"api_key"},
+		rows:    [][]driver.Value{{"abcdef"}},
+	}
+	sql.Register("sqlmask-fake", sqlmask.Wrap(d, sqlmask.Config{}))
+	db, err := sql.Open("sqlmask-fake", "")
+	assert.NoError(t, err)
+	defer db.Close()
+	var apiKey string
+
+	// act
+	tx, err := db.BeginTx(context.Background(), nil)
+	assert.NoError(t, err)
+	result, execErr := tx.Exec("UPDATE keys SET rotated = 1")
+	queryErr := tx.QueryRow("SELECT api_key FROM keys").Scan(&apiKey)
+	commitErr := tx.Commit()
+
+	// assert
+	assert.NoError(t, execErr)
+	affected, _ := result.RowsAffected()
+	assert.Equal(t, int64(1), affected)
+	assert.NoError(t, queryErr)
+	assert.Equal(t, "******", apiKey)
+	assert.NoError(t, commitErr)
+}
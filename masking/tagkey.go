@@ -0,0 +1,69 @@
+package masking
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// defaultTagKey is the struct tag key of masker tags, as in `mask:"X"`.
+const defaultTagKey = "mask"
+
+// SetTagKeys sets the struct tag keys of masker tags read by DefaultRegistry.
+func SetTagKeys(keys ...string) error {
+	return DefaultRegistry.SetTagKeys(keys...)
+}
+
+// SetTagKeys sets the struct tag keys of masker tags read by r, in place of "mask".
+//
+// Keys are given in order of precedence, and the first key present on a field, either as is or as a
+// profile tag, gives the field's masker tag. This allows masker tags to coexist with other uses of a
+// key, such as a vendored type that uses "mask" for another library, or separate tag families for
+// different pipelines:
+//
+//	type User struct {
+//		Email string `json:"email" pii:"X,showfront=2" redact:"*"`
+//	}
+//
+// Tag keys may also be set for a single call with the TagKeys option.
+func (r *Registry) SetTagKeys(keys ...string) error {
+	if err := validateTagKeys(keys); err != nil {
+		return err
+	}
+	r.tagKeys = append([]string(nil), keys...)
+	return nil
+}
+
+func validateTagKeys(keys []string) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("mask: no tag keys given")
+	}
+	for _, key := range keys {
+		if key == "" || strings.ContainsFunc(key, invalidTagKeyRune) {
+			return fmt.Errorf("mask: invalid tag key \"%s\"", key)
+		}
+	}
+	return nil
+}
+
+// invalidTagKeyRune reports whether c may not appear in a struct tag key.
+func invalidTagKeyRune(c rune) bool {
+	return c <= ' ' || c == ':' || c == '"' || c == 0x7f
+}
+
+// structMaskTag returns the masker tag of field given by the first of tagKeys present on the field, and
+// whether one is present. For each key, the "<key>.<profile>" tag takes precedence over the "<key>" tag.
+// An empty profile tag is present, while an empty tag is not.
+func structMaskTag(field reflect.StructField, tagKeys []string, profile string) (string, bool) {
+	for _, key := range tagKeys {
+		if profile != "" {
+			if maskTag, found := field.Tag.Lookup(key + "." + profile); found {
+				return maskTag, true
+			}
+		}
+		if maskTag := field.Tag.Get(key); maskTag != "" {
+			return maskTag, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,108 @@
+package masking_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SetTagKeys_WithMultipleKeys_UsesFirstKeyPresent(t *testing.T) {
+	// arrange
+	r := masking.NewRegistry()
+	err := r.SetTagKeys("pii", "redact")
+	assert.NoError(t, err)
+	type User struct {
+		Name  string `json:"name" mask:"unrelated library spec"`
+		Email string `json:"email" pii:"X,showfront=2" redact:"*"`
+		Phone string `json:"phone" redact:"X,showback=4"`
+	}
+	user := User{Name: "Jane", Email: "jane@example.com", Phone: "555-123-4567"}
+
+	// act
+	err = r.Mask(&user)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, User{Name: "Jane", Email: "jaXXXXXXXXXXXXXX", Phone: "XXXXXXXX4567"}, user)
+}
+
+func Test_SetTagKeys_WithProfile_UsesProfileTagOfKey(t *testing.T) {
+	// arrange
+	r := masking.NewRegistry()
+	err := r.SetTagKeys("redact")
+	assert.NoError(t, err)
+	type User struct {
+		Email string `redact:"*"`
+		Phone string `redact:"X,showback=4" redact.support:""`
+	}
+	user := User{Email: "jane@example.com", Phone: "555-123-4567"}
+
+	// act
+	err = r.MaskFor(context.Background(), "support", &user)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, User{Email: "****************", Phone: "555-123-4567"}, user)
+}
+
+func Test_TagKeys_OverridesRegistryTagKeys(t *testing.T) {
+	// arrange
+	r := masking.NewRegistry()
+	err := r.SetTagKeys("pii")
+	assert.NoError(t, err)
+	type User struct {
+		Email string `pii:"X,showfront=2" redact:"*"`
+	}
+	user := User{Email: "jane@example.com"}
+
+	// act
+	err = r.Mask(&user, masking.TagKeys("redact"))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "****************", user.Email)
+}
+
+func Test_Fmt_WithRegistryTagKeys_PrintsMaskedRepresentation(t *testing.T) {
+	// arrange
+	r := masking.NewRegistry()
+	err := r.SetTagKeys("pii")
+	assert.NoError(t, err)
+	type User struct {
+		Name  string `mask:"unrelated library spec"`
+		Email string `pii:"X,showfront=2"`
+		Phone string `redact:"X,showback=4"`
+	}
+	user := User{Name: "Jane", Email: "jane@example.com", Phone: "555-123-4567"}
+
+	// act
+	out := fmt.Sprintf("%v", r.Fmt(user))
+
+	// assert
+	assert.Equal(t, "{Jane jaXXXXXXXXXXXXXX 555-123-4567}", out)
+}
+
+func Test_SetTagKeys_WithInvalidKeys_ReturnsError(t *testing.T) {
+	// arrange
+	testCases := map[string][]string{
+		"no keys":        {},
+		"empty key":      {"pii", ""},
+		"key with colon": {"pii:"},
+		"key with space": {"p ii"},
+	}
+
+	for name, keys := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := masking.NewRegistry()
+
+			// act
+			err := r.SetTagKeys(keys...)
+
+			// assert
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package masking
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// RegisterTypeMasker registers a masker function for every value of type T.
+//
+// The masker is applied wherever a value of type T is reached while masking, whether or not the field
+// holding it is tagged. A mask tag on a field of type T takes precedence over the type masker.
+func RegisterTypeMasker[T any](masker func(*T) error) error {
+	return RegisterTypeMaskerIn(DefaultRegistry, masker)
+}
+
+// RegisterTypeMaskerIn registers a masker function with r for every value of type T.
+func RegisterTypeMaskerIn[T any](r *Registry, masker func(*T) error) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+
+	_, found := r.typeMaskers[t]
+	if found {
+		return fmt.Errorf("type masker already exists for type: %s", t)
+	}
+
+	r.typeMaskers[t] = func(_ context.Context, ptr reflect.Value) error {
+		return masker(ptr.Interface().(*T))
+	}
+	return nil
+}
+
+// typeMaskerFor returns the type masker of r for the value pointed to by ptr, if any.
+func (r *Registry) typeMaskerFor(ptr reflect.Value) (maskFunc, bool) {
+	if len(r.typeMaskers) == 0 || ptr.Kind() != reflect.Pointer || ptr.IsNil() || !ptr.CanInterface() {
+		return nil, false
+	}
+	typeMaskFunc, found := r.typeMaskers[ptr.Type().Elem()]
+	return typeMaskFunc, found
+}
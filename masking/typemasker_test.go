@@ -0,0 +1,118 @@
+package masking_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/dgravesa/go-mask/masking"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RegisterTypeMasker_MasksEveryOccurrenceOfType(t *testing.T) {
+	// arrange
+	type SSN string
+	maskSSN := func(s *SSN) error {
+		*s = "XXX-XX-" + (*s)[len(*s)-4:]
+		return nil
+	}
+	type Dependent struct {
+		Name string
+		SSN  SSN
+	}
+	type Employee struct {
+		SSN        SSN
+		Dependents []Dependent
+		Spouse     *Dependent
+	}
+	r := masking.NewRegistry()
+	err := masking.RegisterTypeMaskerIn(r, maskSSN)
+	assert.NoError(t, err)
+	e := Employee{
+		SSN: "123-45-6789",
+		Dependents: []Dependent{
+			{Name: "Kid", SSN: "987-65-4321"},
+		},
+		Spouse: &Dependent{Name: "Spouse", SSN: "111-22-3333"},
+	}
+
+	// act
+	err = r.DeepMask(&e)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, Employee{
+		SSN: "XXX-XX-6789",
+		Dependents: []Dependent{
+			{Name: "Kid", SSN: "XXX-XX-4321"},
+		},
+		Spouse: &Dependent{Name: "Spouse", SSN: "XXX-XX-3333"},
+	}, e)
+}
+
+func Test_RegisterTypeMasker_WithStructType_MasksStruct(t *testing.T) {
+	// arrange
+	type Contact struct {
+		Phone sql.NullString
+	}
+	r := masking.NewRegistry()
+	err := masking.RegisterTypeMaskerIn(r, func(ns *sql.NullString) error {
+		if ns.Valid {
+			ns.String = "REDACTED"
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	c := Contact{
+		Phone: sql.NullString{String: "555-1234", Valid: true},
+	}
+
+	// act
+	err = r.Mask(&c)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, sql.NullString{String: "REDACTED", Valid: true}, c.Phone)
+}
+
+func Test_RegisterTypeMasker_WithTaggedField_PrefersTag(t *testing.T) {
+	// arrange
+	type SSN string
+	maskSSN := func(s *SSN) error {
+		*s = "XXX-XX-" + (*s)[len(*s)-4:]
+		return nil
+	}
+	type Record struct {
+		SSN SSN `mask:"*"`
+	}
+	r := masking.NewRegistry()
+	err := masking.RegisterTypeMaskerIn(r, maskSSN)
+	assert.NoError(t, err)
+	rec := Record{
+		SSN: "123-45-6789",
+	}
+
+	// act
+	err = r.Mask(&rec)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, SSN("***********"), rec.SSN)
+}
+
+func Test_RegisterTypeMasker_WithExistingType_ReturnsError(t *testing.T) {
+	// arrange
+	type SSN string
+	maskSSN := func(s *SSN) error {
+		*s = "XXX-XX-" + (*s)[len(*s)-4:]
+		return nil
+	}
+	r := masking.NewRegistry()
+	err := masking.RegisterTypeMaskerIn(r, maskSSN)
+	assert.NoError(t, err)
+
+	// act
+	err = masking.RegisterTypeMaskerIn(r, maskSSN)
+
+	// assert
+	assert.Error(t, err)
+}